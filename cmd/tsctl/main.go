@@ -3,26 +3,50 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/teamycloud/tsctl/pkg/tsctl"
 	"github.com/teamycloud/tsctl/pkg/tsctl/auth"
+	"github.com/teamycloud/tsctl/pkg/tsctl/i18n"
+	"github.com/teamycloud/tsctl/pkg/version"
 )
 
-var rootCmd = &cobra.Command{
-	Use:   "tsctl",
-	Short: "tinyscale - your container runtime on the cloud",
-	Long:  `Utilities for managing and connecting container hosts on the tinyscale platform`,
-}
+// newRootCommand builds the command tree, it must be called after the
+// language has been selected since the help texts are resolved eagerly
+func newRootCommand() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:     "tsctl",
+		Short:   i18n.T("root.short"),
+		Long:    i18n.T("root.long"),
+		Version: version.Version,
+	}
+
+	// The flag is parsed by i18n.LanguageFromArgs before the command tree is
+	// built, it is registered here so cobra accepts it and lists it in help
+	rootCmd.PersistentFlags().String(i18n.LangFlag, "", i18n.T("root.flag.lang"))
 
-func init() {
 	rootCmd.AddCommand(tsctl.NewDaemonCommand())
 	rootCmd.AddCommand(tsctl.NewHostExecCommand())
+	rootCmd.AddCommand(tsctl.NewPortForwardCommand())
+	rootCmd.AddCommand(tsctl.NewSyncCommand())
 	rootCmd.AddCommand(auth.NewAuthCommand())
+
+	return rootCmd
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
+	i18n.Init(i18n.LanguageFromArgs(os.Args[1:]))
+
+	// When invoked through a docker-credential-tinyscale symlink, act as a
+	// Docker credential helper instead of the regular CLI
+	name := strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+	if name == auth.DockerCredentialHelperName {
+		auth.ServeDockerCredentialHelper(os.Args[1:])
+	}
+
+	if err := newRootCommand().Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -0,0 +1,151 @@
+package mutagen_bridge
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mutagen-io/mutagen/pkg/selection"
+	"github.com/mutagen-io/mutagen/pkg/url"
+	"github.com/teamycloud/tsctl/pkg/docker-proxy/types"
+)
+
+const (
+	// AdHocLabel marks the sessions created from the CLI rather than for a
+	// container, its value is the kind of session, e.g. "ad-hoc=port-forward".
+	// The reconciler ignores them since they carry no container-id label.
+	AdHocLabel = "ad-hoc"
+
+	adHocPortForward = "port-forward"
+)
+
+// CreateAdHocForward forwards a local port to a host and port reachable from
+// the remote host, over the configured transport. The session outlives
+// containers and is only terminated on request or when the daemon stops.
+func (m *PortForwardManager) CreateAdHocForward(req types.PortForwardRequest, promptIdentifier string) (types.SessionStatus, error) {
+	// Addresses other than loopback ones require AllowPublicPorts
+	listenAddress := m.listenAddress(req.ListenAddress)
+	remoteHost := req.RemoteHost
+	if remoteHost == "" {
+		remoteHost = localhostAddress
+	}
+	for _, port := range []string{req.LocalPort, req.RemotePort} {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return types.SessionStatus{}, fmt.Errorf("invalid port %q", port)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Fail early with a clear error rather than leaving a session that cannot
+	// listen
+	listener, err := net.Listen("tcp", net.JoinHostPort(listenAddress, req.LocalPort))
+	if err != nil {
+		return types.SessionStatus{}, fmt.Errorf("local port %s is not available: %w", req.LocalPort, err)
+	}
+	_ = listener.Close()
+
+	source, err := url.Parse(localForwardEndpoint(listenAddress, req.LocalPort), url.Kind_Forwarding, true)
+	if err != nil {
+		return types.SessionStatus{}, fmt.Errorf("invalid forwarding source: %w", err)
+	}
+	destination, err := m.remoteForwardURL("tcp:" + net.JoinHostPort(remoteHost, req.RemotePort))
+	if err != nil {
+		return types.SessionStatus{}, fmt.Errorf("invalid forwarding destination: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s", adHocPortForward, req.LocalPort)
+	resetForwardCreateConfiguration(name)
+	sessionLabels := map[string]string{AdHocLabel: adHocPortForward}
+	session, err := m.createForwardSession(source, destination, sessionLabels, promptIdentifier)
+	if err != nil {
+		return types.SessionStatus{}, err
+	}
+
+	m.logger.Infof("Created ad-hoc port forwarding session %s from local %s to remote %s", session,
+		net.JoinHostPort(listenAddress, req.LocalPort), net.JoinHostPort(remoteHost, req.RemotePort))
+	return types.SessionStatus{
+		Identifier:  session,
+		Name:        name,
+		Kind:        types.SessionKindForward,
+		Source:      formatSessionURL(source),
+		Destination: formatSessionURL(destination),
+		Labels:      sessionLabels,
+		AdHoc:       true,
+	}, nil
+}
+
+// Sessions returns all the forwarding sessions of the daemon, sorted by name
+func (m *PortForwardManager) Sessions() ([]types.SessionStatus, error) {
+	_, states, err := m.mutagenForwardMgr.List(context.Background(), &selection.Selection{All: true}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list forwarding sessions: %w", err)
+	}
+
+	sessions := make([]types.SessionStatus, 0, len(states))
+	for _, state := range states {
+		sessions = append(sessions, types.SessionStatus{
+			Identifier:  state.Session.Identifier,
+			Name:        state.Session.Name,
+			Kind:        types.SessionKindForward,
+			Source:      formatSessionURL(state.Session.Source),
+			Destination: formatSessionURL(state.Session.Destination),
+			Labels:      state.Session.Labels,
+			AdHoc:       state.Session.Labels[AdHocLabel] != "",
+			Status:      state.Status.Description(),
+			LastError:   state.LastError,
+		})
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Name < sessions[j].Name
+	})
+	return sessions, nil
+}
+
+// TerminateAdHocForwards terminates the ad-hoc forwarding sessions whose
+// identifier or name is spec. It returns false when no forwarding session
+// matches. Sessions of containers are refused, they are managed with the
+// containers.
+func (m *PortForwardManager) TerminateAdHocForwards(spec string) (bool, error) {
+	_, states, err := m.mutagenForwardMgr.List(context.Background(), &selection.Selection{All: true}, 0)
+	if err != nil {
+		return false, fmt.Errorf("failed to list forwarding sessions: %w", err)
+	}
+
+	var identifiers []string
+	for _, state := range states {
+		if state.Session.Identifier != spec && state.Session.Name != spec {
+			continue
+		}
+		if state.Session.Labels[AdHocLabel] == "" {
+			return true, fmt.Errorf("session %s is managed by the daemon for a container and cannot be terminated", spec)
+		}
+		identifiers = append(identifiers, state.Session.Identifier)
+	}
+	if len(identifiers) == 0 {
+		return false, nil
+	}
+
+	selected := &selection.Selection{Specifications: identifiers}
+	if err := m.mutagenForwardMgr.Terminate(context.Background(), selected, ""); err != nil {
+		return true, fmt.Errorf("unable to terminate session %s: %w", spec, err)
+	}
+	m.logger.Infof("✗ Terminated ad-hoc forwarding session %s", spec)
+	return true, nil
+}
+
+// formatSessionURL formats the URL of a session endpoint for display. Mutagen
+// does not know how to format Tinyscale URLs.
+func formatSessionURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	if u.Protocol == url.Protocol_Tinyscale {
+		return fmt.Sprintf("ts://%s/%s", net.JoinHostPort(u.Host, strconv.Itoa(int(u.Port))), strings.TrimPrefix(u.Path, "/"))
+	}
+	return u.Format("")
+}
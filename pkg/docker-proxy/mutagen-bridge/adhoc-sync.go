@@ -0,0 +1,208 @@
+package mutagen_bridge
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mutagen-io/mutagen/pkg/selection"
+	"github.com/teamycloud/tsctl/pkg/docker-proxy/types"
+)
+
+const adHocSync = "sync"
+
+// AdHocSyncMount validates an ad-hoc sync request and returns the mount it
+// synchronizes, the remote path defaults to the one a bind mount of the
+// directory would use
+func AdHocSyncMount(req types.SyncRequest) (*BindMount, error) {
+	if !filepath.IsAbs(req.LocalPath) {
+		return nil, fmt.Errorf("local path %q is not absolute", req.LocalPath)
+	}
+	info, err := os.Stat(req.LocalPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to access local path: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("local path %s is not a directory", req.LocalPath)
+	}
+
+	remotePath := RemotePath(req.LocalPath)
+	if req.RemotePath != "" {
+		if !path.IsAbs(req.RemotePath) {
+			return nil, fmt.Errorf("remote path %q is not absolute", req.RemotePath)
+		}
+		remotePath = path.Clean(req.RemotePath)
+	}
+	if remotePath == "/" {
+		return nil, fmt.Errorf("refusing to synchronize to the remote root directory")
+	}
+	localPath := filepath.Clean(req.LocalPath)
+	if reason := sensitiveHostPath(localPath); reason != "" {
+		return nil, fmt.Errorf("refusing to synchronize %s, %s; synchronize a project directory instead", localPath, reason)
+	}
+
+	return &BindMount{
+		HostPath:   localPath,
+		RemotePath: remotePath,
+	}, nil
+}
+
+// CheckAdHocSync refuses an ad-hoc sync whose remote path overlaps the one of
+// a bind mount of a container known to the daemon, two sessions would write
+// the same remote files
+func (m *FileSyncManager) CheckAdHocSync(mount *BindMount) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for containerID, containerMounts := range m.containerMounts {
+		for _, bind := range containerMounts.Mounts {
+			if !bind.Socket && remotePathsOverlap(bind.RemotePath, mount.RemotePath) {
+				return fmt.Errorf("%s is already synchronized to %s for container %s, remove the container or synchronize to another remote path",
+					bind.HostPath, bind.RemotePath, containerID[:min(12, len(containerID))])
+			}
+		}
+	}
+	return nil
+}
+
+// adHocSyncRemotePaths returns the remote paths of the ad-hoc sync sessions
+// by session name
+func (m *FileSyncManager) adHocSyncRemotePaths() map[string]string {
+	remotePaths := make(map[string]string)
+	_, states, err := m.mutagenSyncMgr.List(context.Background(), &selection.Selection{
+		LabelSelector: AdHocLabel + "=" + adHocSync,
+	}, 0)
+	if err != nil {
+		m.logger.Debugf("Unable to list ad-hoc sync sessions: %v", err)
+		return remotePaths
+	}
+	for _, state := range states {
+		name := state.Session.Name
+		if name == "" {
+			name = state.Session.Identifier
+		}
+		remotePaths[name] = strings.TrimSuffix(state.Session.Beta.Path, "/")
+	}
+	return remotePaths
+}
+
+// remotePathsOverlap checks if one of two remote paths contains the other
+func remotePathsOverlap(a, b string) bool {
+	return a == b || strings.HasPrefix(a, strings.TrimSuffix(b, "/")+"/") || strings.HasPrefix(b, strings.TrimSuffix(a, "/")+"/")
+}
+
+// CreateAdHocSync synchronizes a local directory to the remote host. A
+// session already synchronizing the same paths is returned instead of
+// creating another one.
+func (m *FileSyncManager) CreateAdHocSync(mount *BindMount, promptIdentifier string) (types.SessionStatus, error) {
+	m.createMu.Lock()
+	defer m.createMu.Unlock()
+
+	_, states, err := m.mutagenSyncMgr.List(context.Background(), &selection.Selection{
+		LabelSelector: AdHocLabel + "=" + adHocSync,
+	}, 0)
+	if err == nil {
+		for _, state := range states {
+			if state.Session.Alpha.Path == mount.HostPath && strings.TrimSuffix(state.Session.Beta.Path, "/") == mount.RemotePath {
+				return syncSessionStatus(state.Session.Identifier, state.Session.Name, state.Session.Labels, mount), nil
+			}
+		}
+	}
+
+	name := "sync-" + sessionNameComponent(filepath.Base(mount.HostPath))
+	sessionLabels := []string{AdHocLabel + "=" + adHocSync}
+	session, err := m.createSyncSession(name, sessionLabels, mount, promptIdentifier)
+	if err != nil {
+		return types.SessionStatus{}, err
+	}
+	return syncSessionStatus(session, name, map[string]string{AdHocLabel: adHocSync}, mount), nil
+}
+
+// syncSessionStatus describes a newly created ad-hoc sync session
+func syncSessionStatus(identifier, name string, labels map[string]string, mount *BindMount) types.SessionStatus {
+	return types.SessionStatus{
+		Identifier:  identifier,
+		Name:        name,
+		Kind:        types.SessionKindSync,
+		Source:      mount.HostPath,
+		Destination: mount.RemotePath,
+		Labels:      labels,
+		AdHoc:       true,
+	}
+}
+
+// Sessions returns all the synchronization sessions of the daemon, sorted by
+// name
+func (m *FileSyncManager) Sessions() ([]types.SessionStatus, error) {
+	_, states, err := m.mutagenSyncMgr.List(context.Background(), &selection.Selection{All: true}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sync sessions: %w", err)
+	}
+
+	sessions := make([]types.SessionStatus, 0, len(states))
+	for _, state := range states {
+		sessions = append(sessions, types.SessionStatus{
+			Identifier:  state.Session.Identifier,
+			Name:        state.Session.Name,
+			Kind:        types.SessionKindSync,
+			Source:      formatSessionURL(state.Session.Alpha),
+			Destination: formatSessionURL(state.Session.Beta),
+			Labels:      state.Session.Labels,
+			AdHoc:       state.Session.Labels[AdHocLabel] != "",
+			Status:      state.Status.Description(),
+			LastError:   state.LastError,
+		})
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Name < sessions[j].Name
+	})
+	return sessions, nil
+}
+
+// TerminateAdHocSyncs terminates the ad-hoc synchronization sessions whose
+// identifier or name is spec. It returns false when no synchronization
+// session matches. Sessions of containers are refused, they are managed with
+// the containers.
+func (m *FileSyncManager) TerminateAdHocSyncs(spec string) (bool, error) {
+	_, states, err := m.mutagenSyncMgr.List(context.Background(), &selection.Selection{All: true}, 0)
+	if err != nil {
+		return false, fmt.Errorf("failed to list sync sessions: %w", err)
+	}
+
+	var identifiers []string
+	for _, state := range states {
+		if state.Session.Identifier != spec && state.Session.Name != spec {
+			continue
+		}
+		if state.Session.Labels[AdHocLabel] == "" {
+			return true, fmt.Errorf("session %s is managed by the daemon for a container and cannot be terminated", spec)
+		}
+		identifiers = append(identifiers, state.Session.Identifier)
+	}
+	if len(identifiers) == 0 {
+		return false, nil
+	}
+
+	selected := &selection.Selection{Specifications: identifiers}
+	if err := m.mutagenSyncMgr.Terminate(context.Background(), selected, ""); err != nil {
+		return true, fmt.Errorf("unable to terminate session %s: %w", spec, err)
+	}
+	m.logger.Infof("✗ Terminated ad-hoc sync session %s", spec)
+	return true, nil
+}
+
+// sessionNameComponent replaces the characters mutagen does not allow in
+// session names, e.g. the dots of "my.project"
+func sessionNameComponent(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) || r == '-' {
+			return r
+		}
+		return '-'
+	}, s)
+}
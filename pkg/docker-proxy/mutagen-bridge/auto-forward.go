@@ -0,0 +1,106 @@
+package mutagen_bridge
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mutagen-io/mutagen/pkg/selection"
+)
+
+// AutoForwardLabel set to "false" excludes a container from auto-forward
+const AutoForwardLabel = "tinyscale.auto-forward"
+
+// SyncAutoForwards forwards the TCP ports a running container listens on, as
+// reported by the guest, and tears down the auto forwards of ports that
+// closed. Ports are reached on remoteHost from the remote host, the address
+// of the container, and forwarded on the same local port number when it is
+// free. Published ports are forwarded already and skipped.
+func (m *PortForwardManager) SyncAutoForwards(containerID string, meta ContainerMeta, remoteHost string, ports []int, promptIdentifier string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	containerPorts, exists := m.containerPorts[containerID]
+	if !exists {
+		containerPorts = &ContainerPorts{
+			ContainerID: containerID,
+			Name:        strings.TrimPrefix(meta.Name, "/"),
+			Labels:      meta.Labels,
+		}
+	}
+
+	listening := make(map[string]bool)
+	for _, port := range ports {
+		listening[strconv.Itoa(port)] = true
+	}
+
+	// Tear down the forwards of closed ports
+	known := make(map[string]bool)
+	bindings := make([]*PortBinding, 0, len(containerPorts.Bindings))
+	for _, binding := range containerPorts.Bindings {
+		if binding.Auto && !listening[binding.ContainerPort] {
+			close(binding.StopCh)
+			if binding.SessionID != "" {
+				if err := m.terminateForwardSession(binding.SessionID); err != nil {
+					m.logger.Infof("Error terminating auto forward %s: %s", binding.LocalPort, err)
+				}
+			}
+			m.logger.Infof("✗ Closed auto forward: %s -> %s/tcp of container %s",
+				net.JoinHostPort(binding.HostIP, binding.LocalPort), binding.ContainerPort, containerID)
+			continue
+		}
+		if binding.Protocol == "tcp" {
+			known[binding.ContainerPort] = true
+		}
+		bindings = append(bindings, binding)
+	}
+	containerPorts.Bindings = bindings
+
+	sort.Ints(ports)
+	for _, port := range ports {
+		containerPort := strconv.Itoa(port)
+		if known[containerPort] {
+			continue
+		}
+		known[containerPort] = true
+
+		hostIP := m.defaultHostIP(containerPorts)
+		localPort, err := m.allocateLocalPort("tcp", hostIP, containerPort, containerPorts.Bindings)
+		if err != nil {
+			m.logger.Infof("Ignored listening port %s of container %s: %v", containerPort, containerID, err)
+			continue
+		}
+
+		binding := newPortBinding(hostIP, containerPort, localPort, containerPort, "tcp")
+		binding.RemoteHost = remoteHost
+		binding.Auto = true
+		if localPort != containerPort {
+			binding.Warning = fmt.Sprintf("listening port %s/tcp is forwarded on local port %s", containerPort, localPort)
+		}
+
+		sessionID, err := m.setupSingleForward(containerID, binding, promptIdentifier)
+		if err != nil {
+			m.logger.Infof("Failed to setup auto forward %s: %v", localPort, err)
+			binding.Warning = fmt.Sprintf("unable to forward local port %s/tcp: %v", localPort, err)
+		}
+		binding.SessionID = sessionID
+		containerPorts.Bindings = append(containerPorts.Bindings, binding)
+		m.logger.Infof("Auto-forwarded %s -> %s/tcp of container %s",
+			net.JoinHostPort(hostIP, localPort), containerPort, containerID)
+	}
+
+	if len(containerPorts.Bindings) > 0 {
+		m.containerPorts[containerID] = containerPorts
+	}
+}
+
+// terminateForwardSession terminates a forwarding session by its identifier
+func (m *PortForwardManager) terminateForwardSession(sessionID string) error {
+	selected := &selection.Selection{
+		Specifications: []string{sessionID},
+	}
+	return m.mutagenForwardMgr.Terminate(context.Background(), selected, "")
+}
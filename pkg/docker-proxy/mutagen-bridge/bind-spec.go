@@ -0,0 +1,229 @@
+package mutagen_bridge
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MountTypeBind is the type of mounts backed by a host path
+	MountTypeBind = "bind"
+	// MountTypeVolume is the type of mounts backed by a named or anonymous volume
+	MountTypeVolume = "volume"
+
+	// uncRemoteDir is the directory under SyncBasePath holding Windows UNC paths
+	uncRemoteDir = "unc"
+)
+
+// MountSpec is a parsed bind mount or volume specification, either from the
+// `source:target[:options]` form of HostConfig.Binds and `-v`, or built from
+// the entries of HostConfig.Mounts that `--mount` is sent as
+type MountSpec struct {
+	Type    string   // MountTypeBind or MountTypeVolume
+	Source  string   // Host path, volume name, or empty for anonymous volumes
+	Target  string   // Path inside the container
+	Options []string // Options in their original order, e.g. "ro", "z", "rshared"
+}
+
+// IsBind checks if the spec mounts a host path
+func (s *MountSpec) IsBind() bool {
+	return s.Type == MountTypeBind
+}
+
+// ReadOnly checks if the spec carries a read-only option, either `ro` or
+// `readonly` alone or with a boolean value as accepted by `--mount`, e.g.
+// `readonly=true` or `ro=1`
+func (s *MountSpec) ReadOnly() bool {
+	readOnly := false
+	for _, option := range s.Options {
+		key, value, hasValue := strings.Cut(option, "=")
+		switch strings.ToLower(key) {
+		case "ro", "readonly":
+			if !hasValue {
+				readOnly = true
+			} else if parsed, err := strconv.ParseBool(value); err == nil {
+				readOnly = parsed
+			}
+		case "rw":
+			readOnly = false
+		}
+	}
+	return readOnly
+}
+
+// String formats the spec in the `source:target[:options]` form
+func (s *MountSpec) String() string {
+	spec := s.Target
+	if s.Source != "" {
+		spec = s.Source + ":" + spec
+	}
+	if len(s.Options) > 0 {
+		spec = spec + ":" + strings.Join(s.Options, ",")
+	}
+	return spec
+}
+
+// ParseBindSpec parses a `[source:]target[:options]` specification, as found
+// in HostConfig.Binds. Sources may be POSIX paths, Windows drive paths such as
+// `C:\src` or `C:/src`, UNC paths such as `\\server\share`, or volume names.
+func ParseBindSpec(spec string) (*MountSpec, error) {
+	if spec == "" {
+		return nil, fmt.Errorf("empty mount specification")
+	}
+
+	first, rest := splitSpecField(spec)
+	if rest == nil {
+		// A lone target is an anonymous volume
+		if !isAbsoluteTarget(first) {
+			return nil, fmt.Errorf("invalid mount specification %q: target must be an absolute path", spec)
+		}
+		return &MountSpec{Type: MountTypeVolume, Target: first}, nil
+	}
+
+	result := &MountSpec{Source: first, Type: MountTypeVolume}
+	if isHostPath(first) {
+		result.Type = MountTypeBind
+	}
+
+	target, rest := splitSpecField(*rest)
+	if !isAbsoluteTarget(target) {
+		// `/data:ro` is an anonymous volume with options
+		if rest == nil && isAbsoluteTarget(result.Source) {
+			return &MountSpec{Type: MountTypeVolume, Target: result.Source, Options: splitOptions(target)}, nil
+		}
+		return nil, fmt.Errorf("invalid mount specification %q: target must be an absolute path", spec)
+	}
+	result.Target = target
+
+	if rest != nil {
+		if strings.Contains(*rest, ":") {
+			return nil, fmt.Errorf("invalid mount specification %q: too many fields", spec)
+		}
+		result.Options = splitOptions(*rest)
+	}
+
+	if result.Type == MountTypeVolume && !isVolumeName(result.Source) {
+		return nil, fmt.Errorf("invalid mount specification %q: %q is neither an absolute path nor a volume name", spec, result.Source)
+	}
+
+	return result, nil
+}
+
+// RemotePath maps a local host path to its POSIX path under SyncBasePath on
+// the remote host. Windows drive paths map to a lower-cased drive directory,
+// `C:\src\app` to `<SyncBasePath>/c/src/app`, and UNC paths map under a
+// `unc` directory, `\\server\share` to `<SyncBasePath>/unc/server/share`.
+func RemotePath(hostPath string) string {
+	var remote string
+	switch {
+	case isDrivePath(hostPath):
+		drive := strings.ToLower(hostPath[:1])
+		remote = "/" + drive + "/" + strings.ReplaceAll(hostPath[2:], `\`, "/")
+	case isUNCPath(hostPath):
+		remote = "/" + uncRemoteDir + "/" + strings.ReplaceAll(hostPath[2:], `\`, "/")
+	default:
+		remote = "/" + hostPath
+	}
+	return path.Join(SyncBasePath, remote)
+}
+
+// LocalPathFromRemote reverses RemotePath. It returns false if the remote
+// path is not under SyncBasePath.
+func LocalPathFromRemote(remotePath string) (string, bool) {
+	if remotePath != SyncBasePath && !strings.HasPrefix(remotePath, SyncBasePath+"/") {
+		return "", false
+	}
+	local := strings.TrimPrefix(remotePath, SyncBasePath)
+	if local == "" {
+		local = "/"
+	}
+	if runtime.GOOS != "windows" {
+		return local, true
+	}
+
+	components := strings.SplitN(strings.TrimPrefix(local, "/"), "/", 2)
+	rest := ""
+	if len(components) == 2 {
+		rest = strings.ReplaceAll(components[1], "/", `\`)
+	}
+	if components[0] == uncRemoteDir {
+		return `\\` + rest, true
+	}
+	if len(components[0]) == 1 {
+		return strings.ToUpper(components[0]) + `:\` + rest, true
+	}
+	return "", false
+}
+
+// splitSpecField splits the first field off a colon-separated spec, keeping
+// the colon of a leading Windows drive letter. The rest is nil if the spec
+// has a single field.
+func splitSpecField(spec string) (string, *string) {
+	start := 0
+	if isDrivePath(spec) {
+		start = 2
+	}
+	i := strings.Index(spec[start:], ":")
+	if i < 0 {
+		return spec, nil
+	}
+	rest := spec[start+i+1:]
+	return spec[:start+i], &rest
+}
+
+// splitOptions splits a comma-separated option list, dropping empty entries
+func splitOptions(options string) []string {
+	var result []string
+	for _, option := range strings.Split(options, ",") {
+		if option = strings.TrimSpace(option); option != "" {
+			result = append(result, option)
+		}
+	}
+	return result
+}
+
+// isHostPath checks if a mount source is a host path rather than a volume name
+func isHostPath(source string) bool {
+	return strings.HasPrefix(source, "/") || isDrivePath(source) || isUNCPath(source)
+}
+
+// isAbsoluteTarget checks if a mount target is an absolute container path,
+// accepting drive paths for Windows containers
+func isAbsoluteTarget(target string) bool {
+	return strings.HasPrefix(target, "/") || isDrivePath(target)
+}
+
+// isDrivePath checks if p starts with a Windows drive letter, e.g. `C:\` or `C:/`
+func isDrivePath(p string) bool {
+	if len(p) < 3 || p[1] != ':' || (p[2] != '\\' && p[2] != '/') {
+		return false
+	}
+	c := p[0]
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isUNCPath checks if p is a Windows UNC path, e.g. `\\server\share`, or
+// `//server/share` when running on Windows
+func isUNCPath(p string) bool {
+	if len(p) <= 2 {
+		return false
+	}
+	return strings.HasPrefix(p, `\\`) || (runtime.GOOS == "windows" && strings.HasPrefix(p, "//"))
+}
+
+// isVolumeName checks if name is a valid Docker volume name
+func isVolumeName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		alnum := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+		if !alnum && (i == 0 || (c != '_' && c != '.' && c != '-')) {
+			return false
+		}
+	}
+	return true
+}
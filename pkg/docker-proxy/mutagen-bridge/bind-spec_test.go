@@ -0,0 +1,195 @@
+package mutagen_bridge
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestParseBindSpec(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want *MountSpec
+	}{
+		{
+			name: "posix path",
+			spec: "/src:/app",
+			want: &MountSpec{Type: MountTypeBind, Source: "/src", Target: "/app"},
+		},
+		{
+			name: "posix path with options",
+			spec: "/src:/app:ro,z",
+			want: &MountSpec{Type: MountTypeBind, Source: "/src", Target: "/app", Options: []string{"ro", "z"}},
+		},
+		{
+			name: "empty options are dropped",
+			spec: "/src:/app:ro,,rshared",
+			want: &MountSpec{Type: MountTypeBind, Source: "/src", Target: "/app", Options: []string{"ro", "rshared"}},
+		},
+		{
+			name: "drive path with backslashes",
+			spec: `C:\src\app:/app`,
+			want: &MountSpec{Type: MountTypeBind, Source: `C:\src\app`, Target: "/app"},
+		},
+		{
+			name: "drive path with slashes and options",
+			spec: "c:/src:/app:rw",
+			want: &MountSpec{Type: MountTypeBind, Source: "c:/src", Target: "/app", Options: []string{"rw"}},
+		},
+		{
+			name: "drive path to windows container",
+			spec: `C:\src:C:\app`,
+			want: &MountSpec{Type: MountTypeBind, Source: `C:\src`, Target: `C:\app`},
+		},
+		{
+			name: "unc path",
+			spec: `\\server\share\dir:/data:ro`,
+			want: &MountSpec{Type: MountTypeBind, Source: `\\server\share\dir`, Target: "/data", Options: []string{"ro"}},
+		},
+		{
+			name: "named volume",
+			spec: "my-data_1.0:/data",
+			want: &MountSpec{Type: MountTypeVolume, Source: "my-data_1.0", Target: "/data"},
+		},
+		{
+			name: "named volume with options",
+			spec: "cache:/cache:nocopy",
+			want: &MountSpec{Type: MountTypeVolume, Source: "cache", Target: "/cache", Options: []string{"nocopy"}},
+		},
+		{
+			name: "anonymous volume",
+			spec: "/data",
+			want: &MountSpec{Type: MountTypeVolume, Target: "/data"},
+		},
+		{
+			name: "anonymous volume with options",
+			spec: "/data:ro",
+			want: &MountSpec{Type: MountTypeVolume, Target: "/data", Options: []string{"ro"}},
+		},
+		{name: "empty", spec: ""},
+		{name: "relative target", spec: "/src:app:ro"},
+		{name: "relative anonymous volume", spec: "data"},
+		{name: "too many fields", spec: "/src:/app:ro:z"},
+		{name: "invalid volume name", spec: "-data:/data"},
+		{name: "relative source", spec: "./src:/app"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseBindSpec(test.spec)
+			if test.want == nil {
+				if err == nil {
+					t.Fatalf("ParseBindSpec(%q) = %+v, want an error", test.spec, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseBindSpec(%q) failed: %v", test.spec, err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ParseBindSpec(%q) = %+v, want %+v", test.spec, got, test.want)
+			}
+		})
+	}
+}
+
+func TestMountSpecReadOnly(t *testing.T) {
+	tests := []struct {
+		options []string
+		want    bool
+	}{
+		{nil, false},
+		{[]string{"ro"}, true},
+		{[]string{"readonly"}, true},
+		{[]string{"readonly=true"}, true},
+		{[]string{"ro=true"}, true},
+		{[]string{"readonly=1"}, true},
+		{[]string{"readonly=false"}, false},
+		{[]string{"ro=0"}, false},
+		{[]string{"readonly=invalid"}, false},
+		{[]string{"ro", "rw"}, false},
+		{[]string{"rw", "ro"}, true},
+		{[]string{"z", "rshared"}, false},
+	}
+
+	for _, test := range tests {
+		spec := &MountSpec{Type: MountTypeBind, Source: "/src", Target: "/app", Options: test.options}
+		if got := spec.ReadOnly(); got != test.want {
+			t.Errorf("ReadOnly() with options %q = %v, want %v", test.options, got, test.want)
+		}
+	}
+}
+
+func TestMountSpecString(t *testing.T) {
+	for _, spec := range []string{"/src:/app", "/src:/app:ro,z", `C:\src:/app`, "cache:/cache", "/data"} {
+		parsed, err := ParseBindSpec(spec)
+		if err != nil {
+			t.Fatalf("ParseBindSpec(%q) failed: %v", spec, err)
+		}
+		if got := parsed.String(); got != spec {
+			t.Errorf("String() of %q = %q", spec, got)
+		}
+	}
+}
+
+func TestRemotePath(t *testing.T) {
+	tests := []struct {
+		hostPath string
+		want     string
+	}{
+		{"/Users/user/project", SyncBasePath + "/Users/user/project"},
+		{"/Users/user/project/", SyncBasePath + "/Users/user/project"},
+		{"/", SyncBasePath},
+		{`C:\src\app`, SyncBasePath + "/c/src/app"},
+		{"D:/src/app", SyncBasePath + "/d/src/app"},
+		{`\\server\share\dir`, SyncBasePath + "/unc/server/share/dir"},
+	}
+
+	for _, test := range tests {
+		if got := RemotePath(test.hostPath); got != test.want {
+			t.Errorf("RemotePath(%q) = %q, want %q", test.hostPath, got, test.want)
+		}
+	}
+}
+
+func TestLocalPathFromRemote(t *testing.T) {
+	tests := []struct {
+		remotePath string
+		want       string
+		ok         bool
+	}{
+		{SyncBasePath + "/Users/user/project", "/Users/user/project", true},
+		{SyncBasePath, "/", true},
+		{"/opt/other/project", "", false},
+		{SyncBasePath + "-other/project", "", false},
+	}
+	if runtime.GOOS == "windows" {
+		tests = []struct {
+			remotePath string
+			want       string
+			ok         bool
+		}{
+			{SyncBasePath + "/c/src/app", `C:\src\app`, true},
+			{SyncBasePath + "/unc/server/share/dir", `\\server\share\dir`, true},
+			{SyncBasePath + "/users/project", "", false},
+			{"/opt/other/project", "", false},
+		}
+	}
+
+	for _, test := range tests {
+		got, ok := LocalPathFromRemote(test.remotePath)
+		if got != test.want || ok != test.ok {
+			t.Errorf("LocalPathFromRemote(%q) = %q, %v, want %q, %v", test.remotePath, got, ok, test.want, test.ok)
+		}
+	}
+
+	// Local paths of this platform survive the round trip
+	hostPath := "/Users/user/project"
+	if runtime.GOOS == "windows" {
+		hostPath = `C:\Users\user\project`
+	}
+	if got, ok := LocalPathFromRemote(RemotePath(hostPath)); !ok || got != hostPath {
+		t.Errorf("LocalPathFromRemote(RemotePath(%q)) = %q, %v", hostPath, got, ok)
+	}
+}
@@ -0,0 +1,74 @@
+package mutagen_bridge
+
+import (
+	"fmt"
+	"hash/fnv"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DependencyVolumePrefix prefixes the names of the remote volumes holding the
+// dependency directories of bind mounts
+const DependencyVolumePrefix = "tinyscale-deps-"
+
+// DependencyVolume is a remote named volume mounted over a dependency
+// directory of a bind mount, such as node_modules, so that dependencies are
+// installed and stay on the remote host instead of being synced
+type DependencyVolume struct {
+	Name   string // Volume name, stable for a host directory
+	Target string // Container path, e.g. "/app/node_modules"
+}
+
+// dependencyDirs returns the dependency subdirectories of a bind mounted
+// directory, the ones of its project file or else the ones of the daemon
+func (m *FileSyncManager) dependencyDirs(hostPath string) []string {
+	dirs := strings.Split(m.transportConfig.DependencyDirs, ",")
+	if project, _, err := loadProjectConfig(hostPath); err == nil && project != nil && project.Dependencies != nil {
+		dirs = project.Dependencies
+	}
+
+	var valid []string
+	for _, dir := range dirs {
+		dir = strings.TrimSpace(dir)
+		if dir == "" {
+			continue
+		}
+		dir = path.Clean(filepath.ToSlash(dir))
+		if path.IsAbs(dir) || dir == "." || dir == ".." || strings.HasPrefix(dir, "../") {
+			m.logger.Warnf("Ignored dependency directory %q, it must be relative to the bind mount", dir)
+			continue
+		}
+		valid = append(valid, dir)
+	}
+	return valid
+}
+
+// DependencyVolumes returns the volumes to mount over the dependency
+// directories of the mounts
+func (c *ContainerMounts) DependencyVolumes() []DependencyVolume {
+	var volumes []DependencyVolume
+	for _, mount := range c.Mounts {
+		for _, dir := range mount.DependencyDirs {
+			volumes = append(volumes, DependencyVolume{
+				Name:   dependencyVolumeName(mount.HostPath, dir),
+				Target: path.Join(mount.ContainerPath, dir),
+			})
+		}
+	}
+	return volumes
+}
+
+// dependencyVolumeName names the volume of a dependency directory after the
+// directory and a hash of its local path, e.g. "tinyscale-deps-web-node_modules-1a2b3c4d"
+func dependencyVolumeName(hostPath string, dir string) string {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(filepath.Join(hostPath, filepath.FromSlash(dir))))
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '.' || r == '-' {
+			return r
+		}
+		return '-'
+	}, filepath.Base(hostPath)+"-"+strings.ReplaceAll(dir, "/", "-"))
+	return fmt.Sprintf("%s%s-%08x", DependencyVolumePrefix, name, hash.Sum32())
+}
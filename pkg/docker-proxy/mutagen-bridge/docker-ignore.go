@@ -0,0 +1,84 @@
+package mutagen_bridge
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core/ignore/docker"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core/ignore/mutagen"
+)
+
+// DockerIgnoreFile lists the paths excluded from the Docker build context
+const DockerIgnoreFile = ".dockerignore"
+
+// dockerIgnoreKeeps are unignored after the patterns of a .dockerignore,
+// Docker reads them even when they are excluded from the build context
+var dockerIgnoreKeeps = []string{"!Dockerfile", "!" + DockerIgnoreFile}
+
+// loadDockerIgnore reads the .dockerignore of a synced directory into ignore
+// patterns of the docker syntax. It returns nil when the directory has no
+// .dockerignore.
+func loadDockerIgnore(dir string) ([]string, error) {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		// Single files have no .dockerignore
+		return nil, nil
+	}
+	file, err := os.Open(filepath.Join(dir, DockerIgnoreFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		pattern := strings.TrimSpace(scanner.Text())
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		// Patterns mutagen rejects, such as backslash-separated paths, would
+		// fail the whole session
+		if err := docker.EnsurePatternValid(pattern); err != nil {
+			continue
+		}
+		patterns = append(patterns, pattern)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	return append(patterns, dockerIgnoreKeeps...), nil
+}
+
+// dockerIgnoreToMutagen translates docker syntax patterns to the mutagen
+// syntax. Docker patterns are relative to the root of the synced directory,
+// they become mutagen patterns anchored with a leading slash, e.g. "*.log"
+// only matches at the root in both syntaxes. Patterns matching the root
+// itself are dropped.
+func dockerIgnoreToMutagen(patterns []string) []string {
+	var translated []string
+	for _, pattern := range patterns {
+		pattern, negation := strings.CutPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(path.Clean(pattern), "/")
+		if pattern == "." || pattern == "" {
+			continue
+		}
+		pattern = "/" + pattern
+		if negation {
+			pattern = "!" + pattern
+		}
+		if err := mutagen.EnsurePatternValid(pattern); err != nil {
+			continue
+		}
+		translated = append(translated, pattern)
+	}
+	return translated
+}
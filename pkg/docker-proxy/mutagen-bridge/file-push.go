@@ -0,0 +1,223 @@
+package mutagen_bridge
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+)
+
+const (
+	// filePushPath is the guest endpoint writing a file in place
+	filePushPath = "/tinyscale/v1/host-exec/file"
+	// filePushInterval is how often pushed files are checked for changes
+	filePushInterval = time.Second
+	// filePushTimeout bounds the push of a single file
+	filePushTimeout = time.Minute
+)
+
+// Single-file bind mounts, such as "./nginx.conf:/etc/nginx/nginx.conf", are
+// pushed to the remote host by the daemon instead of being synced by a
+// mutagen session. The file is pushed when the container is created, so that
+// Docker does not create a directory at the missing bind source, and written
+// in place whenever it changes locally: mutagen replaces files by renaming
+// new ones over them, which containers bind mounting the previous file never
+// see. Files of mounts with a sync mode label are synced by a mutagen session
+// of the single file instead, e.g. databases written by the container.
+
+// pushedFile is a single-file bind mount pushed for the containers binding it
+type pushedFile struct {
+	HostPath   string
+	RemotePath string
+	ModTime    time.Time       // Modification time of the last pushed content
+	Size       int64           // Size of the last pushed content
+	Mode       os.FileMode     // Permissions of the last pushed content
+	LastError  string          // Last push failure, logged once
+	Containers map[string]bool // IDs of the containers binding the file
+}
+
+// guestWriteFileRequest is the payload of filePushPath
+type guestWriteFileRequest struct {
+	Path string `json:"path"`
+	Mode uint32 `json:"mode"`
+	Size int64  `json:"size"`
+}
+
+// guestWriteFileResult is sent back by the guest once the file is written
+type guestWriteFileResult struct {
+	Error string `json:"error"`
+}
+
+// pushesFile returns whether the host path of a mount is pushed rather than
+// synced
+func (mount *BindMount) pushesFile() bool {
+	return mount.File && mount.Sync.Mode == ""
+}
+
+// PushFiles pushes the single-file bind mounts of a container being created
+func (m *FileSyncManager) PushFiles(mounts *ContainerMounts) {
+	for _, mount := range mounts.Mounts {
+		if !mount.pushesFile() {
+			continue
+		}
+		if _, err := m.pushFile(mount.HostPath, mount.RemotePath); err != nil {
+			m.logger.Warnf("Failed to push %s: %v", mount.HostPath, err)
+		}
+	}
+}
+
+// retainPush adds a container to the users of a pushed file, and starts
+// watching the file for changes. New files are pushed by the next check. The
+// caller must hold the lock.
+func (m *FileSyncManager) retainPush(containerID string, mount *BindMount) {
+	file, ok := m.files[mount.HostPath]
+	if !ok {
+		file = &pushedFile{
+			HostPath:   mount.HostPath,
+			RemotePath: mount.RemotePath,
+			Containers: make(map[string]bool),
+		}
+		m.files[mount.HostPath] = file
+		m.logger.Infof("Pushing file %s to %s", mount.HostPath, mount.RemotePath)
+	}
+	file.Containers[containerID] = true
+
+	if !m.pushing {
+		m.pushing = true
+		go m.pushLoop()
+	}
+}
+
+// releasePushes removes a container from the users of the pushed files, the
+// files no container binds anymore are not pushed anymore. The caller must
+// hold the lock.
+func (m *FileSyncManager) releasePushes(containerID string) {
+	for hostPath, file := range m.files {
+		if !file.Containers[containerID] {
+			continue
+		}
+		delete(file.Containers, containerID)
+		if len(file.Containers) == 0 {
+			delete(m.files, hostPath)
+			m.logger.Infof("✗ Stopped pushing file %s", hostPath)
+		}
+	}
+}
+
+// pushLoop pushes the files changed locally until no file is pushed anymore.
+// The pushed files are copied under the lock, and pushed without it.
+func (m *FileSyncManager) pushLoop() {
+	ticker := time.NewTicker(filePushInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		m.mu.Lock()
+		if len(m.files) == 0 {
+			m.pushing = false
+			m.mu.Unlock()
+			return
+		}
+		files := make([]pushedFile, 0, len(m.files))
+		for _, file := range m.files {
+			files = append(files, *file)
+		}
+		m.mu.Unlock()
+
+		for i := range files {
+			m.pushIfChanged(&files[i])
+		}
+	}
+}
+
+// pushIfChanged pushes a file whose size, modification time or permissions
+// changed since it was last pushed, from a copy of the pushed file, and
+// records the result on the pushed file if it is still pushed. The caller
+// must not hold the lock.
+func (m *FileSyncManager) pushIfChanged(file *pushedFile) {
+	info, err := os.Stat(file.HostPath)
+	if err == nil && info.ModTime().Equal(file.ModTime) && info.Size() == file.Size && info.Mode().Perm() == file.Mode {
+		return
+	}
+	if err == nil {
+		info, err = m.pushFile(file.HostPath, file.RemotePath)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pushed, ok := m.files[file.HostPath]
+	if !ok {
+		return
+	}
+	if err != nil {
+		// Retried on the next check, only logged when the failure changes
+		if err.Error() != pushed.LastError {
+			pushed.LastError = err.Error()
+			m.logger.Warnf("Failed to push %s: %v", file.HostPath, err)
+		}
+		return
+	}
+
+	pushed.ModTime, pushed.Size, pushed.Mode, pushed.LastError = info.ModTime(), info.Size(), info.Mode().Perm(), ""
+	m.logger.Debugf("Pushed %s to %s", file.HostPath, file.RemotePath)
+}
+
+// pushFile writes the content of a local file to a remote file through the
+// guest agent, with the same permissions. It returns the local file
+// information at the time it was read.
+func (m *FileSyncManager) pushFile(hostPath, remotePath string) (os.FileInfo, error) {
+	m.mu.RLock()
+	dialer := m.guestDialer
+	m.mu.RUnlock()
+	if dialer == nil {
+		return nil, fmt.Errorf("the guest agent is not reachable")
+	}
+
+	local, err := os.Open(hostPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file: %w", err)
+	}
+	defer local.Close()
+	info, err := local.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("unable to stat file: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", hostPath)
+	}
+
+	stream, err := dialer(filePushPath, &guestWriteFileRequest{
+		Path: remotePath,
+		Mode: uint32(info.Mode().Perm()),
+		Size: info.Size(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open guest stream: %w", err)
+	}
+	defer stream.Close()
+	defer setStreamDeadline(stream, filePushTimeout)()
+
+	if _, err := io.CopyN(stream, local, info.Size()); err != nil {
+		return nil, fmt.Errorf("unable to send file: %w", err)
+	}
+	var result guestWriteFileResult
+	if err := json.NewDecoder(stream).Decode(&result); err != nil {
+		return nil, fmt.Errorf("unable to read result: %w", err)
+	}
+	if result.Error != "" {
+		return nil, fmt.Errorf("%s", result.Error)
+	}
+	return info, nil
+}
+
+// setStreamDeadline bounds the use of a guest stream. Streams not supporting
+// deadlines, such as SSH channels, are closed once the timeout expires
+// instead. The returned function stops the timer.
+func setStreamDeadline(stream net.Conn, timeout time.Duration) func() {
+	if err := stream.SetDeadline(time.Now().Add(timeout)); err == nil {
+		return func() {}
+	}
+	timer := time.AfterFunc(timeout, func() { stream.Close() })
+	return func() { timer.Stop() }
+}
@@ -6,7 +6,9 @@ import (
 	"net/http"
 	neturl "net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 
@@ -31,10 +33,16 @@ const SyncBasePath = "/opt/container-mount-sync"
 
 // BindMount represents a volume mount from local to remote
 type BindMount struct {
-	HostPath      string // Local path (e.g., "/Users/user/project")
-	ContainerPath string // Container path (e.g., "/app")
-	ReadOnly      bool   // Whether the mount is read-only
-	SessionID     string // Mutagen sync session ID
+	HostPath       string      // Local path (e.g., "/Users/user/project")
+	RemotePath     string      // Path on the remote host (e.g., "/opt/container-mount-sync/Users/user/project")
+	ContainerPath  string      // Container path (e.g., "/app")
+	ReadOnly       bool        // Whether the mount is read-only
+	Socket         bool        // Whether the host path is a Unix socket, forwarded instead of synced
+	File           bool        // Whether the host path is a regular file, pushed instead of synced without a sync mode
+	SessionID      string      // Mutagen sync session ID
+	Sync           SyncOptions // Sync settings from the container labels
+	DependencyDirs []string    // Subdirectories kept on remote volumes instead of synced, e.g. "node_modules"
+	NestedPaths    []string    // Nested mounts pushed or synced by a session of their own, e.g. "dist"
 }
 
 // ContainerMounts tracks bind mounts for a specific container
@@ -43,13 +51,27 @@ type ContainerMounts struct {
 	Mounts      []*BindMount
 }
 
+// sharedSync is the sync session of a host path, shared by all the
+// containers binding it
+type sharedSync struct {
+	SessionID  string
+	Options    *SyncOptions    // Sync settings of the session, nil when adopted from a previous run
+	Ignorer    ignore.Ignorer  // Ignores of the session, nil when unknown
+	Containers map[string]bool // IDs of the containers using the session
+}
+
 // FileSyncManager manages file synchronization for bind mounts
 type FileSyncManager struct {
 	mu              sync.RWMutex
+	createMu        sync.Mutex                         // Serializes the creation of sessions
 	containers      map[*http.Request]*ContainerMounts // httpReq -> mounts
 	containerMounts map[string]*ContainerMounts        // containerID -> mounts
+	syncs           map[string]*sharedSync             // host path -> session
+	files           map[string]*pushedFile             // host path -> pushed file
+	pushing         bool                               // Whether the push loop runs
 	transportConfig types.Config
 	mutagenSyncMgr  *synchronization.Manager
+	guestDialer     GuestDialer
 	logger          *logging.Logger
 }
 
@@ -58,6 +80,8 @@ func NewFileSyncBridge(remoteConfig types.Config, synchronizationManager *synchr
 	return &FileSyncManager{
 		containers:      make(map[*http.Request]*ContainerMounts),
 		containerMounts: make(map[string]*ContainerMounts),
+		syncs:           make(map[string]*sharedSync),
+		files:           make(map[string]*pushedFile),
 		transportConfig: remoteConfig,
 		mutagenSyncMgr:  synchronizationManager,
 		logger:          logger,
@@ -65,58 +89,13 @@ func NewFileSyncBridge(remoteConfig types.Config, synchronizationManager *synchr
 }
 
 // StoreBindMountsStart stores the bind mounts for a container (before it's created)
-func (m *FileSyncManager) StoreBindMountsStart(req *http.Request, binds []string) {
+func (m *FileSyncManager) StoreBindMountsStart(req *http.Request, binds []string, options map[string]SyncOptions) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	mounts := make([]*BindMount, 0)
-	mountNameMap := make(map[string]*BindMount)
-	for _, bind := range binds {
-		// Parse bind mount syntax: /host/path:/container/path[:ro]
-		parts := strings.Split(bind, ":")
-		if len(parts) < 2 {
-			m.logger.Infof("Ignored invalid bind mount format: %s", bind)
-			continue
-		}
-
-		hostPath := parts[0]
-		containerPath := parts[1]
-		readOnly := false
-
-		if len(parts) >= 3 && parts[2] == "ro" {
-			readOnly = true
-		}
-
-		// Expand host path to absolute path
-		absHostPath, err := filepath.Abs(hostPath)
-		if err != nil {
-			m.logger.Infof("Ignored failure on resolving host path %s: %v", hostPath, err)
-			continue
-		}
-
-		// Check if the host path exists
-		if _, err := os.Stat(absHostPath); err != nil {
-			m.logger.Infof("Ignored non-existance host path: %s", absHostPath)
-			continue
-		}
-
-		if existing, ok := mountNameMap[absHostPath]; ok {
-			if existing.ReadOnly && !readOnly {
-				existing.ReadOnly = false
-			}
-
-			// for one host path, we at most setup one sync
-			continue
-		}
-
-		mount := &BindMount{
-			HostPath:      absHostPath,
-			ContainerPath: containerPath,
-			ReadOnly:      readOnly,
-		}
-		mountNameMap[absHostPath] = mount
-		mounts = append(mounts, mount)
-		m.logger.Debugf("Stored bind mount: %s -> %s (ro=%v)", absHostPath, containerPath, readOnly)
+	mounts := m.parseBindMounts(binds, options)
+	for _, mount := range mounts {
+		m.logger.Debugf("Stored bind mount: %s -> %s (ro=%v)", mount.HostPath, mount.ContainerPath, mount.ReadOnly)
 	}
 
 	if len(mounts) > 0 {
@@ -141,41 +120,56 @@ func (m *FileSyncManager) StoreBindMountsEnd(req *http.Request, containerID stri
 }
 
 // StoreBindMountsForContainer stores bind mounts directly for a container ID
-func (m *FileSyncManager) StoreBindMountsForContainer(containerID string, binds []string) {
+func (m *FileSyncManager) StoreBindMountsForContainer(containerID string, binds []string, options map[string]SyncOptions) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	mounts := m.parseBindMounts(binds, options)
+	for _, mount := range mounts {
+		m.logger.Debugf("Stored bind mount for container %s: %s -> %s (ro=%v)", containerID, mount.HostPath, mount.ContainerPath, mount.ReadOnly)
+	}
+
+	if len(mounts) > 0 {
+		containerMounts := &ContainerMounts{
+			ContainerID: containerID,
+			Mounts:      mounts,
+		}
+		m.containerMounts[containerID] = containerMounts
+	}
+}
+
+// parseBindMounts parses bind specifications into the bind mounts to sync,
+// skipping volumes and host paths that do not exist. Sync options are matched
+// by container path.
+func (m *FileSyncManager) parseBindMounts(binds []string, options map[string]SyncOptions) []*BindMount {
 	mounts := make([]*BindMount, 0)
 	mountNameMap := make(map[string]*BindMount)
 	for _, bind := range binds {
-		// Parse bind mount syntax: /host/path:/container/path[:ro]
-		parts := strings.Split(bind, ":")
-		if len(parts) < 2 {
-			m.logger.Infof("Ignored invalid bind mount format: %s", bind)
+		spec, err := ParseBindSpec(bind)
+		if err != nil {
+			m.logger.Infof("Ignored invalid bind mount format: %v", err)
 			continue
 		}
-
-		hostPath := parts[0]
-		containerPath := parts[1]
-		readOnly := false
-
-		if len(parts) >= 3 && parts[2] == "ro" {
-			readOnly = true
+		if !spec.IsBind() {
+			// Named and anonymous volumes live on the remote host
+			continue
 		}
 
 		// Expand host path to absolute path
-		absHostPath, err := filepath.Abs(hostPath)
+		absHostPath, err := filepath.Abs(spec.Source)
 		if err != nil {
-			m.logger.Infof("Ignored failure on resolving host path %s: %v", hostPath, err)
+			m.logger.Infof("Ignored failure on resolving host path %s: %v", spec.Source, err)
 			continue
 		}
 
 		// Check if the host path exists
-		if _, err := os.Stat(absHostPath); err != nil {
+		info, err := os.Stat(absHostPath)
+		if err != nil {
 			m.logger.Infof("Ignored non-existent host path: %s", absHostPath)
 			continue
 		}
 
+		readOnly := spec.ReadOnly()
 		if existing, ok := mountNameMap[absHostPath]; ok {
 			if existing.ReadOnly && !readOnly {
 				existing.ReadOnly = false
@@ -187,21 +181,21 @@ func (m *FileSyncManager) StoreBindMountsForContainer(containerID string, binds
 
 		mount := &BindMount{
 			HostPath:      absHostPath,
-			ContainerPath: containerPath,
+			RemotePath:    RemotePath(absHostPath),
+			ContainerPath: spec.Target,
 			ReadOnly:      readOnly,
+			Socket:        info.Mode()&os.ModeSocket != 0,
+			File:          info.Mode().IsRegular(),
+			Sync:          options[path.Clean(spec.Target)],
+		}
+		if info.IsDir() {
+			mount.DependencyDirs = m.dependencyDirs(absHostPath)
 		}
 		mountNameMap[absHostPath] = mount
 		mounts = append(mounts, mount)
-		m.logger.Debugf("Stored bind mount for container %s: %s -> %s (ro=%v)", containerID, absHostPath, containerPath, readOnly)
-	}
-
-	if len(mounts) > 0 {
-		containerMounts := &ContainerMounts{
-			ContainerID: containerID,
-			Mounts:      mounts,
-		}
-		m.containerMounts[containerID] = containerMounts
 	}
+	setNestedPaths(mounts)
+	return mounts
 }
 
 // GetMounts returns all the mounts for a container
@@ -215,30 +209,159 @@ func (m *FileSyncManager) GetMounts(key *http.Request) *ContainerMounts {
 	return nil
 }
 
-// SetupSyncs sets up file synchronization sessions for a container
+// SocketPaths returns the host paths of the Unix sockets among the bind
+// mounts of a container
+func (m *FileSyncManager) SocketPaths(containerID string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	containerMounts, exists := m.containerMounts[containerID]
+	if !exists {
+		return nil
+	}
+	return containerMounts.SocketPaths()
+}
+
+// SocketPaths returns the host paths of the Unix sockets among the mounts
+func (c *ContainerMounts) SocketPaths() []string {
+	var paths []string
+	for _, mount := range c.Mounts {
+		if mount.Socket {
+			paths = append(paths, mount.HostPath)
+		}
+	}
+	return paths
+}
+
+// SetupSyncs sets up file synchronization sessions for a container. Sessions
+// are created one at a time, and the lock is only held to look up and record
+// them, not during their bulk upload and creation.
 func (m *FileSyncManager) SetupSyncs(containerID string, promptIdentifier string) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.createMu.Lock()
+	defer m.createMu.Unlock()
 
 	m.logger.Debugf("Setting up file syncs for container %s", containerID)
+	m.mu.RLock()
 	containerMounts, exists := m.containerMounts[containerID]
+	m.mu.RUnlock()
 	if !exists {
 		m.logger.Debugf("No bind mounts found for container %s", containerID)
 		return nil
 	}
 
-	for _, mount := range containerMounts.Mounts {
+	for _, mount := range rootsFirst(containerMounts.Mounts) {
+		if mount.Socket {
+			// Sockets such as $SSH_AUTH_SOCK are forwarded by the port forward manager
+			continue
+		}
+		m.mu.Lock()
+		if mount.pushesFile() {
+			// Single files are pushed even within synced directories, the
+			// sessions of which ignore them
+			m.retainPush(containerID, mount)
+			m.mu.Unlock()
+			continue
+		}
+		// A host path is synced once for all the containers binding it or
+		// one of its parent directories
+		root, shared := m.syncRootOf(mount.HostPath)
+		if shared != nil {
+			m.retainSync(root, shared, containerID, mount)
+			m.mu.Unlock()
+			continue
+		}
+		m.ignoreNestedSyncs(mount)
+		m.mu.Unlock()
+
 		sessionID, err := m.setupSingleSync(containerID, mount, promptIdentifier)
 		if err != nil {
 			m.logger.Infof("Failed to setup file sync %s: %v", mount.HostPath, err)
 			// Continue with other mounts even if one fails
+			continue
+		}
+		var ignorer ignore.Ignorer
+		if !mount.File {
+			// Nested mounts ignored by the session get a session of their own
+			if ignorer, err = m.mountIgnorer(mount); err != nil {
+				m.logger.Debugf("Unable to compute the ignores of %s: %v", mount.HostPath, err)
+			}
+		}
+
+		m.mu.Lock()
+		if _, exists := m.containerMounts[containerID]; !exists {
+			// The container was torn down while the session was created
+			m.mu.Unlock()
+			selected := &selection.Selection{Specifications: []string{sessionID}}
+			if err := m.mutagenSyncMgr.Terminate(context.Background(), selected, ""); err != nil {
+				m.logger.Infof("Error terminating sync session %s: %s", sessionID, err)
+			}
+			return nil
 		}
 		mount.SessionID = sessionID
+		options := mount.Sync
+		shared = &sharedSync{
+			SessionID:  sessionID,
+			Options:    &options,
+			Ignorer:    ignorer,
+			Containers: map[string]bool{containerID: true},
+		}
+		m.syncs[mount.HostPath] = shared
+		m.absorbNestedSyncs(mount.HostPath, shared)
+		m.mu.Unlock()
 	}
 
 	return nil
 }
 
+// retainSync adds a container to the users of the session of root, which
+// syncs the host path of the mount. The caller must hold the lock.
+func (m *FileSyncManager) retainSync(root string, shared *sharedSync, containerID string, mount *BindMount) {
+	mount.SessionID = shared.SessionID
+	if !shared.Containers[containerID] {
+		shared.Containers[containerID] = true
+		m.logger.Infof("Sharing sync session %s of %s with container %s for %s (%d containers)",
+			shared.SessionID, root, containerID, mount.HostPath, len(shared.Containers))
+	} else if mount.HostPath != root {
+		m.logger.Debugf("Syncing %s of container %s with the session %s of its parent directory %s",
+			mount.HostPath, containerID, shared.SessionID, root)
+	} else {
+		return
+	}
+	if mount.Sync.Mode != "" || len(mount.Sync.Ignores) > 0 {
+		if shared.Options == nil || shared.Options.Mode != mount.Sync.Mode || !slices.Equal(shared.Options.Ignores, mount.Sync.Ignores) {
+			m.logger.Warnf("Ignored the sync labels of container %s for %s, the session of %s is shared and keeps the settings it was created with",
+				containerID, mount.HostPath, root)
+		}
+	}
+}
+
+// RetainSyncs stores the bind mounts of a running container and adds it to
+// the users of the sync sessions that already exist for their host paths,
+// without creating any session, and resumes pushing its single-file mounts.
+// It is used when reconciling with the remote host, before the sessions of
+// stopped containers are torn down.
+func (m *FileSyncManager) RetainSyncs(containerID string, binds []string, options map[string]SyncOptions) {
+	m.StoreBindMountsForContainer(containerID, binds, options)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	containerMounts, exists := m.containerMounts[containerID]
+	if !exists {
+		return
+	}
+	for _, mount := range containerMounts.Mounts {
+		if mount.Socket {
+			continue
+		}
+		if mount.pushesFile() {
+			m.retainPush(containerID, mount)
+		} else if root, shared := m.syncRootOf(mount.HostPath); shared != nil {
+			m.retainSync(root, shared, containerID, mount)
+		}
+	}
+}
+
 // fsCreateConfiguration stores configuration for the sync session
 var fsCreateConfiguration struct {
 	// help indicates whether or not to show help information and exit.
@@ -408,11 +531,60 @@ func loadAndValidateGlobalSynchronizationConfiguration(path string) (*synchroniz
 	return configuration, nil
 }
 
+// baseSyncConfiguration merges the global configuration, unless disabled,
+// and additional configuration files into the configuration sessions are
+// created from
+func baseSyncConfiguration(noGlobalConfiguration bool, configurationFiles []string) (*synchronization.Configuration, error) {
+	// Create a default session configuration that will form the basis of our
+	// cumulative configuration.
+	configuration := &synchronization.Configuration{}
+
+	// Unless disabled, attempt to load configuration from the global
+	// configuration file and merge it into our cumulative configuration.
+	if !noGlobalConfiguration {
+		// Compute the path to the global configuration file.
+		globalConfigurationPath, err := global.ConfigurationPath()
+		if err != nil {
+			return nil, fmt.Errorf("unable to compute path to global configuration file: %w", err)
+		}
+
+		// Attempt to load the file. We allow it to not exist.
+		globalConfiguration, err := loadAndValidateGlobalSynchronizationConfiguration(globalConfigurationPath)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return nil, fmt.Errorf("unable to load global configuration: %w", err)
+			}
+		} else {
+			configuration = synchronization.MergeConfigurations(configuration, globalConfiguration)
+		}
+	}
+
+	// If additional default configuration files have been specified, then load
+	// them and merge them into the cumulative configuration.
+	for _, configurationFile := range configurationFiles {
+		if c, err := loadAndValidateGlobalSynchronizationConfiguration(configurationFile); err != nil {
+			return nil, fmt.Errorf("unable to load configuration file (%s): %w", configurationFile, err)
+		} else {
+			configuration = synchronization.MergeConfigurations(configuration, c)
+		}
+	}
+	return configuration, nil
+}
+
 // setupSingleSync sets up a single file synchronization session
 func (m *FileSyncManager) setupSingleSync(containerID string, mount *BindMount, promptIdentifier string) (string, error) {
+	name := fmt.Sprintf("sync-%s-%s", containerID[:8], filepath.Base(mount.HostPath))
+	labels := []string{fmt.Sprintf("container-id=%s", compressContainerID(containerID))}
+	return m.createSyncSession(name, labels, mount, promptIdentifier)
+}
+
+// createSyncSession creates a synchronization session from the local path of
+// a mount to its remote path over the configured transport. The caller must
+// hold createMu since fsCreateConfiguration is shared, but not the lock.
+func (m *FileSyncManager) createSyncSession(name string, sessionLabels []string, mount *BindMount, promptIdentifier string) (string, error) {
 	fsCreateConfiguration.help = false
-	fsCreateConfiguration.name = fmt.Sprintf("sync-%s-%s", containerID[:8], filepath.Base(mount.HostPath))
-	fsCreateConfiguration.labels = []string{fmt.Sprintf("container-id=%s", compressContainerID(containerID))}
+	fsCreateConfiguration.name = name
+	fsCreateConfiguration.labels = sessionLabels
 	fsCreateConfiguration.paused = false
 	fsCreateConfiguration.noGlobalConfiguration = false
 	fsCreateConfiguration.configurationFiles = nil
@@ -476,8 +648,7 @@ func (m *FileSyncManager) setupSingleSync(containerID string, mount *BindMount,
 
 	// Destination: remote path
 	// The path will be something like /opt/container-mount-sync/{host-path}
-	// todo: support Windows paths test long paths
-	remotePath := fmt.Sprintf("%s%s", SyncBasePath, mount.HostPath)
+	remotePath := mount.RemotePath
 
 	var beta *url.URL
 
@@ -509,6 +680,11 @@ func (m *FileSyncManager) setupSingleSync(containerID string, mount *BindMount,
 		return "", fmt.Errorf("invalid sync destination: %w", err)
 	}
 
+	// Remote-to-local mounts sync from the remote host
+	if mount.Sync.Mode == SyncModeRemoteToLocal {
+		alpha, beta = beta, alpha
+	}
+
 	if err := selection.EnsureNameValid(fsCreateConfiguration.name); err != nil {
 		return "", fmt.Errorf("invalid session name: %w", err)
 	}
@@ -529,38 +705,47 @@ func (m *FileSyncManager) setupSingleSync(containerID string, mount *BindMount,
 		labels[key] = value
 	}
 
-	// Create a default session configuration that will form the basis of our
-	// cumulative configuration.
-	configuration := &synchronization.Configuration{}
+	// Load the global configuration and the additional configuration files,
+	// which form the basis of our cumulative configuration.
+	configuration, err := baseSyncConfiguration(fsCreateConfiguration.noGlobalConfiguration, fsCreateConfiguration.configurationFiles)
+	if err != nil {
+		return "", err
+	}
 
-	// Unless disabled, attempt to load configuration from the global
-	// configuration file and merge it into our cumulative configuration.
-	if !fsCreateConfiguration.noGlobalConfiguration {
-		// Compute the path to the global configuration file.
-		globalConfigurationPath, err := global.ConfigurationPath()
-		if err != nil {
-			return "", fmt.Errorf("unable to compute path to global configuration file: %w", err)
+	// Merge the project configuration found next to the host path, its
+	// settings replace the defaults of the daemon
+	project, projectPath, err := loadProjectConfig(mount.HostPath)
+	if err != nil {
+		m.logger.Warnf("Ignored project file: %v", err)
+	} else if project != nil {
+		m.logger.Debugf("Using project file %s for %s", projectPath, mount.HostPath)
+		projectConfiguration := project.Sync.ToInternal()
+		configuration = synchronization.MergeConfigurations(configuration, projectConfiguration)
+		if !projectConfiguration.SynchronizationMode.IsDefault() {
+			fsCreateConfiguration.synchronizationMode = ""
 		}
-
-		// Attempt to load the file. We allow it to not exist.
-		globalConfiguration, err := loadAndValidateGlobalSynchronizationConfiguration(globalConfigurationPath)
-		if err != nil {
-			if !os.IsNotExist(err) {
-				return "", fmt.Errorf("unable to load global configuration: %w", err)
-			}
-		} else {
-			configuration = synchronization.MergeConfigurations(configuration, globalConfiguration)
+		if projectConfiguration.DefaultFileMode != 0 {
+			fsCreateConfiguration.defaultFileModeBeta = ""
+		}
+		if projectConfiguration.DefaultDirectoryMode != 0 {
+			fsCreateConfiguration.defaultDirectoryModeBeta = ""
 		}
 	}
 
-	// If additional default configuration files have been specified, then load
-	// them and merge them into the cumulative configuration.
-	for _, configurationFile := range fsCreateConfiguration.configurationFiles {
-		if c, err := loadAndValidateGlobalSynchronizationConfiguration(configurationFile); err != nil {
-			return "", fmt.Errorf("unable to load configuration file (%s): %w", configurationFile, err)
-		} else {
-			configuration = synchronization.MergeConfigurations(configuration, c)
-		}
+	m.addMountIgnores(configuration, project, mount)
+
+	// Settings of the container labels take priority
+	fsCreateConfiguration.ignores = mount.Sync.Ignores
+	switch mount.Sync.Mode {
+	case SyncModeOneWayReplica, SyncModeTwoWaySafe:
+		fsCreateConfiguration.synchronizationMode = mount.Sync.Mode
+	case SyncModeRemoteToLocal:
+		// Unlike one-way-replica, one-way-safe does not delete the local
+		// files missing from the fresh remote directory
+		fsCreateConfiguration.synchronizationMode = "one-way-safe"
+		// The default modes are meant for the remote host, beta is local now
+		fsCreateConfiguration.defaultFileModeBeta = ""
+		fsCreateConfiguration.defaultDirectoryModeBeta = ""
 	}
 
 	// Validate and convert the synchronization mode specification.
@@ -889,6 +1074,9 @@ func (m *FileSyncManager) setupSingleSync(containerID string, mount *BindMount,
 		Paused: fsCreateConfiguration.paused,
 	}
 
+	// Populate a new remote directory in bulk before mutagen takes over
+	m.bootstrapSync(mount, specification)
+
 	session, err := m.mutagenSyncMgr.Create(
 		context.Background(),
 		specification.Alpha,
@@ -905,10 +1093,44 @@ func (m *FileSyncManager) setupSingleSync(containerID string, mount *BindMount,
 		return "", fmt.Errorf("failed to create sync session: %w", err)
 	}
 
-	m.logger.Infof("Created sync session %s: %s -> %s", session, mount.HostPath, remotePath)
+	if mount.Sync.Mode == SyncModeRemoteToLocal {
+		m.logger.Infof("Created sync session %s: %s <- %s", session, mount.HostPath, remotePath)
+	} else {
+		m.logger.Infof("Created sync session %s: %s -> %s", session, mount.HostPath, remotePath)
+	}
 	return session, nil
 }
 
+// addMountIgnores adds the ignores of the directory of a mount to a session
+// configuration, those of its .dockerignore and its dependency directories
+func (m *FileSyncManager) addMountIgnores(configuration *synchronization.Configuration, project *ProjectConfig, mount *BindMount) {
+	// Honor the .dockerignore of the synced directory. Its patterns are
+	// translated to the mutagen syntax unless the session uses the docker
+	// one, the other ignores of the session keep their meaning.
+	if project.dockerIgnoreEnabled() {
+		if patterns, err := loadDockerIgnore(mount.HostPath); err != nil {
+			m.logger.Warnf("Ignored .dockerignore of %s: %v", mount.HostPath, err)
+		} else if len(patterns) > 0 {
+			if configuration.IgnoreSyntax != ignore.Syntax_SyntaxDocker {
+				patterns = dockerIgnoreToMutagen(patterns)
+			}
+			configuration.Ignores = append(configuration.Ignores, patterns...)
+		}
+	}
+
+	// Dependency directories live on remote volumes, nested single files are
+	// pushed, and nested mounts with a sync mode of their own are synced by
+	// their own session
+	for _, dir := range slices.Concat(mount.DependencyDirs, mount.NestedPaths) {
+		if configuration.IgnoreSyntax == ignore.Syntax_SyntaxDocker {
+			configuration.Ignores = append(configuration.Ignores, dir)
+		} else {
+			// Mutagen patterns are anchored to the root with a leading slash
+			configuration.Ignores = append(configuration.Ignores, "/"+dir)
+		}
+	}
+}
+
 // TeardownSyncs tears down file synchronization sessions for a container
 func (m *FileSyncManager) TeardownSyncs(containerID string) {
 	m.mu.Lock()
@@ -918,20 +1140,44 @@ func (m *FileSyncManager) TeardownSyncs(containerID string) {
 
 	m.logger.Infof("Tearing down file syncs for container %s", containerID)
 
+	m.releasePushes(containerID)
+
+	// Sessions shared with other containers are kept until the last one stops
+	var identifiers []string
+	for hostPath, shared := range m.syncs {
+		if !shared.Containers[containerID] {
+			continue
+		}
+		delete(shared.Containers, containerID)
+		if len(shared.Containers) > 0 {
+			m.logger.Infof("Keeping sync session %s of %s for %d other containers", shared.SessionID, hostPath, len(shared.Containers))
+			continue
+		}
+		identifiers = append(identifiers, shared.SessionID)
+		delete(m.syncs, hostPath)
+	}
+	if len(identifiers) == 0 {
+		return
+	}
+
 	selected := &selection.Selection{
 		All:            false,
-		Specifications: []string{},
-		LabelSelector:  fmt.Sprintf("container-id=%s", compressContainerID(containerID)),
+		Specifications: identifiers,
+		LabelSelector:  "",
 	}
 	err := m.mutagenSyncMgr.Terminate(context.Background(), selected, "")
 	if err != nil {
 		m.logger.Infof("Error terminating sync sessions: %s", err)
 	}
-
 }
 
-// ListSessions lists all existing file sync sessions and returns a map of container IDs
+// ListSessions lists all existing file sync sessions and returns a map of container IDs.
+// The sessions of containers left by a previous run are adopted as the shared
+// sessions of their host paths, used by the container that created them.
 func (m *FileSyncManager) ListSessions() (map[string]bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	// Query all synchronization sessions from mutagen
 	sel := &selection.Selection{
 		All: true,
@@ -944,6 +1190,7 @@ func (m *FileSyncManager) ListSessions() (map[string]bool, error) {
 
 	// Extract unique container IDs from session labels
 	containerIDs := make(map[string]bool)
+	var duplicates []string
 	for _, state := range states {
 		if state.Session.Labels != nil {
 			if compressedID, ok := state.Session.Labels["container-id"]; ok {
@@ -953,11 +1200,24 @@ func (m *FileSyncManager) ListSessions() (map[string]bool, error) {
 					containerIDs[containerID] = true
 					m.logger.Debugf("Found existing file sync session for container %s (session: %s)",
 						containerID, state.Session.Identifier)
+					if !m.adoptSync(state.Session, containerID) {
+						duplicates = append(duplicates, state.Session.Identifier)
+					}
 				}
 			}
 		}
 	}
 
+	// Earlier versions synced a host path once per container, the extra
+	// sessions would compete for the same remote directory
+	if len(duplicates) > 0 {
+		m.logger.Infof("Terminating %d duplicate sync sessions", len(duplicates))
+		selected := &selection.Selection{Specifications: duplicates}
+		if err := m.mutagenSyncMgr.Terminate(context.Background(), selected, ""); err != nil {
+			m.logger.Infof("Error terminating duplicate sync sessions: %s", err)
+		}
+	}
+
 	return containerIDs, nil
 }
 
@@ -971,6 +1231,8 @@ func (m *FileSyncManager) TeardownAll() {
 	for containerID := range m.containerMounts {
 		delete(m.containerMounts, containerID)
 	}
+	clear(m.syncs)
+	clear(m.files)
 
 	// Terminate all sync sessions
 	selected := &selection.Selection{
@@ -984,6 +1246,30 @@ func (m *FileSyncManager) TeardownAll() {
 	}
 }
 
+// adoptSync tracks an existing session of a container as the shared session
+// of its local path. It returns false when the path already has a session,
+// which the container is added to. The caller must hold the lock.
+func (m *FileSyncManager) adoptSync(session *synchronization.Session, containerID string) bool {
+	local := session.Alpha
+	if local.Protocol != url.Protocol_Local {
+		// Remote-to-local sessions sync from the remote host
+		local = session.Beta
+	}
+	if shared, ok := m.syncs[local.Path]; ok {
+		shared.Containers[containerID] = true
+		return shared.SessionID == session.Identifier
+	}
+	shared := &sharedSync{
+		SessionID:  session.Identifier,
+		Containers: map[string]bool{containerID: true},
+	}
+	if session.Configuration != nil {
+		shared.Ignorer, _ = syncIgnorer(session.Configuration)
+	}
+	m.syncs[local.Path] = shared
+	return true
+}
+
 // TerminateAllSessions terminates all file sync sessions to ensure nothing remains on disk
 func (m *FileSyncManager) TerminateAllSessions() error {
 	selected := &selection.Selection{
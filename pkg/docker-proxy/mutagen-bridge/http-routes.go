@@ -0,0 +1,118 @@
+package mutagen_bridge
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/teamycloud/tsctl/pkg/docker-proxy/types"
+)
+
+const (
+	// composeProjectLabel and composeServiceLabel are set by Docker Compose
+	composeProjectLabel = "com.docker.compose.project"
+	composeServiceLabel = "com.docker.compose.service"
+
+	// routeDomain is the domain of HTTP routes, resolved to loopback by
+	// browsers and most resolvers without any configuration
+	routeDomain = "localhost"
+)
+
+// HTTPRoutes returns the HTTP routes of the forwarded TCP ports.
+// `<service>.<project>.localhost`, or `<container-name>.localhost` outside of
+// Compose, routes to the lowest forwarded container port, and
+// `<port>.<service>.<project>.localhost` to a specific one.
+func (m *PortForwardManager) HTTPRoutes() []types.HTTPRoute {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	containers := make([]*ContainerPorts, 0, len(m.containerPorts))
+	for _, containerPorts := range m.containerPorts {
+		containers = append(containers, containerPorts)
+	}
+	// Scaled Compose services share a hostname, the first replica gets it
+	sort.Slice(containers, func(i, j int) bool {
+		return containers[i].Name < containers[j].Name
+	})
+
+	seen := make(map[string]bool)
+	routes := make([]types.HTTPRoute, 0)
+	for _, containerPorts := range containers {
+		base := routeBaseName(containerPorts)
+		if base == "" {
+			continue
+		}
+
+		bindings := make([]*PortBinding, 0, len(containerPorts.Bindings))
+		for _, binding := range containerPorts.Bindings {
+			if binding.Protocol == "tcp" && binding.SessionID != "" {
+				bindings = append(bindings, binding)
+			}
+		}
+		sort.Slice(bindings, func(i, j int) bool {
+			a, _ := strconv.Atoi(bindings[i].ContainerPort)
+			b, _ := strconv.Atoi(bindings[j].ContainerPort)
+			return a < b
+		})
+
+		for i, binding := range bindings {
+			hosts := []string{binding.ContainerPort + "." + base + "." + routeDomain}
+			if i == 0 {
+				hosts = append([]string{base + "." + routeDomain}, hosts...)
+			}
+			for _, host := range hosts {
+				if seen[host] {
+					continue
+				}
+				seen[host] = true
+				routes = append(routes, types.HTTPRoute{
+					Host:          host,
+					ContainerID:   containerPorts.ContainerID,
+					ContainerName: containerPorts.Name,
+					ContainerPort: binding.ContainerPort + "/" + binding.Protocol,
+					Target:        net.JoinHostPort(dialAddress(binding.HostIP), binding.LocalPort),
+				})
+			}
+		}
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].Host < routes[j].Host
+	})
+	return routes
+}
+
+// routeBaseName returns `<service>.<project>` for Compose containers, or the
+// container name otherwise
+func routeBaseName(containerPorts *ContainerPorts) string {
+	project := hostnameLabel(containerPorts.Labels[composeProjectLabel])
+	service := hostnameLabel(containerPorts.Labels[composeServiceLabel])
+	if project != "" && service != "" {
+		return service + "." + project
+	}
+	return hostnameLabel(containerPorts.Name)
+}
+
+// hostnameLabel turns a name into a DNS label, e.g. "My_App" -> "my-app"
+func hostnameLabel(name string) string {
+	name = strings.ToLower(strings.TrimPrefix(name, "/"))
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			return r
+		}
+		return '-'
+	}, name)
+}
+
+// dialAddress returns the address to connect to for a listening address,
+// mapping unspecified addresses such as 0.0.0.0 to loopback
+func dialAddress(host string) string {
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		if ip.To4() != nil {
+			return "127.0.0.1"
+		}
+		return "::1"
+	}
+	return host
+}
@@ -0,0 +1,134 @@
+package mutagen_bridge
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net"
+	"strings"
+)
+
+const (
+	// loopbackPrefix is the /16 loopback network per-container addresses are
+	// allocated from
+	loopbackPrefix = "127.77"
+	// loopbackAddresses is the number of addresses in loopbackPrefix, skipping
+	// the .0 and .255 host parts
+	loopbackAddresses = 256 * 254
+	// maxUnusableLoopbacks is the number of unusable addresses skipped before
+	// giving up on finding an aliased one
+	maxUnusableLoopbacks = 256
+
+	// ContainerDomain is the DNS domain under which containers with their own
+	// loopback address are resolvable, as <container-name>.tinyscale.test
+	ContainerDomain = "tinyscale.test"
+)
+
+// defaultHostIP returns the local address of the forwards of a container that
+// did not request a HostIp. With ContainerIPs each container gets its own
+// loopback address, so that containers can publish the same port, otherwise
+// forwards listen on localhost. The caller must hold the lock.
+func (m *PortForwardManager) defaultHostIP(containerPorts *ContainerPorts) string {
+	if !m.transportConfig.ContainerIPs {
+		return localhostAddress
+	}
+
+	if containerPorts.LoopbackIP == "" {
+		ip, err := m.allocateLoopbackIP(containerPorts.Name)
+		if err != nil {
+			m.logger.Warnf("Unable to allocate a loopback address, listening on %s: %v", localhostAddress, err)
+			return localhostAddress
+		}
+		containerPorts.LoopbackIP = ip
+		m.logger.Debugf("Allocated loopback address %s for container %s", ip, containerPorts.Name)
+	}
+	return containerPorts.LoopbackIP
+}
+
+// allocateLoopbackIP picks a free address in loopbackPrefix. Named containers
+// start from a hash of their name, so that a container keeps its address when
+// it is restarted. Addresses that cannot be listened on are skipped, e.g. on
+// macOS where only the addresses aliased on lo0 are usable. Whether the first
+// address is usable is probed once, so that every container create does not
+// probe the whole network when none is. The caller must hold the lock.
+func (m *PortForwardManager) allocateLoopbackIP(name string) (string, error) {
+	if !m.loopbackProbed {
+		m.loopbackProbed = true
+		if first := loopbackPrefix + ".0.1"; !isLoopbackUsable(first) {
+			m.loopbackErr = fmt.Errorf("%s is not configured on the loopback interface; add aliases with `sudo ifconfig lo0 alias %s up` and restart the daemon", first, first)
+		}
+	}
+	if m.loopbackErr != nil {
+		return "", m.loopbackErr
+	}
+
+	used := make(map[string]bool)
+	for _, containerPorts := range m.containerPorts {
+		used[containerPorts.LoopbackIP] = true
+	}
+	for _, containerPorts := range m.containers {
+		used[containerPorts.LoopbackIP] = true
+	}
+
+	start := m.nextLoopbackIndex
+	if name != "" {
+		hash := fnv.New32a()
+		_, _ = hash.Write([]byte(strings.ToLower(name)))
+		start = hash.Sum32()
+	}
+
+	unusable := 0
+	for i := uint32(0); i < loopbackAddresses; i++ {
+		index := (start + i) % loopbackAddresses
+		ip := fmt.Sprintf("%s.%d.%d", loopbackPrefix, index/254, index%254+1)
+		if used[ip] {
+			continue
+		}
+		if !isLoopbackUsable(ip) {
+			if unusable++; unusable == maxUnusableLoopbacks {
+				return "", fmt.Errorf("none of %d free addresses tried in %s.0.0/16 is configured on the loopback interface; add aliases with `sudo ifconfig lo0 alias <address> up`",
+					unusable, loopbackPrefix)
+			}
+			continue
+		}
+		if name == "" {
+			m.nextLoopbackIndex = index + 1
+		}
+		return ip, nil
+	}
+	return "", fmt.Errorf("all addresses in %s.0.0/16 are in use", loopbackPrefix)
+}
+
+// LookupContainerIP resolves <container-name>.tinyscale.test, or the short
+// container ID in place of the name, to the loopback address of a container
+func (m *PortForwardManager) LookupContainerIP(hostname string) (net.IP, bool) {
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+	label, found := strings.CutSuffix(hostname, "."+ContainerDomain)
+	if !found || label == "" {
+		return nil, false
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for containerID, containerPorts := range m.containerPorts {
+		if containerPorts.LoopbackIP == "" {
+			continue
+		}
+		if strings.ToLower(containerPorts.Name) == label || (len(label) >= 12 && strings.HasPrefix(containerID, label)) {
+			return net.ParseIP(containerPorts.LoopbackIP), true
+		}
+	}
+	return nil, false
+}
+
+// isLoopbackUsable checks if a loopback address can be listened on. Linux
+// routes all of 127.0.0.0/8 to the loopback interface, macOS only has
+// 127.0.0.1 unless aliases are added with `ifconfig lo0 alias`.
+func isLoopbackUsable(ip string) bool {
+	listener, err := net.Listen("tcp", net.JoinHostPort(ip, "0"))
+	if err != nil {
+		return false
+	}
+	listener.Close()
+	return true
+}
@@ -8,6 +8,8 @@ import (
 	"net/http"
 	neturl "net/url"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -22,20 +24,57 @@ import (
 	ts_tunnel "github.com/teamycloud/tsctl/pkg/ts-tunnel"
 )
 
+const (
+	// localhostAddress is the default local address port forwards listen on
+	localhostAddress = "localhost"
+
+	// LocalPortLabelPrefix prefixes the container labels recording the local
+	// port of a container port, e.g. "tinyscale.local-port.5432/tcp=5432"
+	LocalPortLabelPrefix = "tinyscale.local-port."
+)
+
 // PortBinding represents a port mapping from local to remote
 type PortBinding struct {
-	HostPort      string // Local port (e.g., "8080")
+	HostIP        string // Local address to listen on (e.g., "localhost", "0.0.0.0", "::1")
+	HostPort      string // Port published on the remote host (e.g., "8080")
+	RemoteHost    string // Remote address HostPort is reached on, localhost when empty
+	LocalPort     string // Local port forwarded to HostPort, usually the same number
 	ContainerPort string // Container port with protocol (e.g., "80/tcp")
-	Protocol      string // tcp or udp, but mutagen does not support udp at the moment. See https://mutagen.io/documentation/forwarding/
+	Protocol      string // tcp or udp, udp is relayed through the guest since mutagen does not support it. See https://mutagen.io/documentation/forwarding/
 	Listener      net.Listener
+	PacketConn    net.PacketConn // Local UDP socket of udp bindings
 	SessionID     string
+	Warning       string // Local port conflict or forwarding failure, reported in the daemon status
+	Auto          bool   // Whether the port was found listening by auto-forward rather than published
 	StopCh        chan struct{}
 }
 
 // ContainerPorts tracks port forwards for a specific container
 type ContainerPorts struct {
-	ContainerID string
-	Bindings    []*PortBinding
+	ContainerID      string
+	Name             string            // Container name, without the leading slash
+	Labels           map[string]string // Container labels, known once the container started
+	LoopbackIP       string            // Loopback address of the container when ContainerIPs is enabled
+	Bindings         []*PortBinding
+	RequestedHostIPs map[string]string // container port -> local address for ports Docker assigns on start
+
+	// RequestedLocalPorts maps a container port (e.g., "5432/tcp") to the
+	// local port it is forwarded on when the remote port is assigned by
+	// Docker, which happens for containers with their own loopback address
+	RequestedLocalPorts map[string]string
+}
+
+// ContainerMeta holds the details of a running container that port forwards
+// depend on, as found in inspect and list responses
+type ContainerMeta struct {
+	Name   string            // Container name, with or without the leading slash
+	Labels map[string]string // Container labels
+}
+
+// HostBinding is a binding of a container port as found in HostConfig.PortBindings
+type HostBinding struct {
+	HostIP   string // Requested bind address, empty for the default
+	HostPort string // Port, port range ("8000-8010"), or empty to let Docker pick one
 }
 
 // PortForwardManager manages port forwards for all containers
@@ -43,8 +82,16 @@ type PortForwardManager struct {
 	mu                sync.RWMutex
 	containers        map[*http.Request]*ContainerPorts // httpPort -> ports
 	containerPorts    map[string]*ContainerPorts        // containerID -> ports
+	reverseForwards   map[string]*ReverseForward        // remote port -> reverse forward
+	socketForwards    map[string]*SocketForward         // local socket path -> socket forward
+	containerSockets  map[string][]string               // containerID -> local socket paths, kept across restarts
+	pendingSockets    map[*http.Request][]string        // httpReq -> local socket paths
 	transportConfig   types.Config
 	mutagenForwardMgr *forwarding.Manager
+	guestDialer       GuestDialer
+	nextLoopbackIndex uint32 // Where the search for the loopback address of an unnamed container starts
+	loopbackProbed    bool   // Whether loopbackErr was probed
+	loopbackErr       error  // Why the addresses of loopbackPrefix are unusable, if they are
 	logger            *logging.Logger
 }
 
@@ -53,55 +100,116 @@ func NewPortForwardBridge(remoteConfig types.Config, forwardingManager *forwardi
 	return &PortForwardManager{
 		containers:        make(map[*http.Request]*ContainerPorts),
 		containerPorts:    make(map[string]*ContainerPorts),
+		reverseForwards:   make(map[string]*ReverseForward),
+		socketForwards:    make(map[string]*SocketForward),
+		containerSockets:  make(map[string][]string),
+		pendingSockets:    make(map[*http.Request][]string),
 		transportConfig:   remoteConfig,
 		mutagenForwardMgr: forwardingManager,
 		logger:            logger,
 	}
 }
 
-// StorePortBindingsStart stores the port bindings for a container (before it's created)
-func (m *PortForwardManager) StorePortBindingsStart(req *http.Request, hostPorts map[string][]string) {
+// StorePortBindingsStart stores the port bindings for a container (before it's created).
+// It returns a warning for each requested local port that is already in use.
+func (m *PortForwardManager) StorePortBindingsStart(req *http.Request, portBindings map[string][]HostBinding) []string {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	bindings := make([]*PortBinding, 0)
-	for containerPort, hostPortList := range hostPorts {
-		if len(hostPortList) == 0 {
-			continue
-		}
+	var warnings []string
+	var reserved []*PortBinding
+	containerPorts := &ContainerPorts{
+		Name:                strings.TrimPrefix(req.URL.Query().Get("name"), "/"),
+		RequestedHostIPs:    make(map[string]string),
+		RequestedLocalPorts: make(map[string]string),
+	}
+	for containerPort, hostBindings := range portBindings {
+		port, protocol := splitContainerPort(containerPort)
 
-		// Extract protocol from container port (e.g., "80/tcp" -> "tcp")
-		parts := strings.Split(containerPort, "/")
-		port := containerPort
-		protocol := "tcp"
-		if len(parts) == 2 {
-			port = parts[0]
-			protocol = parts[1]
-		}
+		for _, hostBinding := range hostBindings {
+			hostIP := m.defaultHostIP(containerPorts)
+			if hostBinding.HostIP != "" {
+				hostIP = m.listenAddress(hostBinding.HostIP)
+			}
 
-		// For now, take the first host port binding
-		for _, hostPort := range hostPortList {
-			if hostPort == "" {
+			pairs, assigned, err := expandPortRange(port, hostBinding.HostPort)
+			if err != nil {
+				m.logger.Infof("Ignored invalid port binding %s -> %s: %v", hostBinding.HostPort, containerPort, err)
+				continue
+			}
+			if assigned {
+				// Docker picks the host port, it is forwarded once the container starts
+				containerPorts.RequestedHostIPs[containerPort] = hostIP
 				continue
 			}
 
-			binding := &PortBinding{
-				HostPort:      hostPort,
-				ContainerPort: port,
-				Protocol:      protocol,
-				StopCh:        make(chan struct{}),
+			if hostBinding.HostIP == "" && hostIP == containerPorts.LoopbackIP {
+				// The container has its own local address, so the requested
+				// port is only needed locally. The remote port is left to
+				// Docker, letting containers publish the same port.
+				for _, pair := range pairs {
+					binding := newPortBinding(hostIP, "", pair.hostPort, pair.containerPort, protocol)
+					if warning, _ := m.checkLocalPort(binding, reserved); warning != "" {
+						warnings = append(warnings, warning)
+					}
+					reserved = append(reserved, binding)
+					containerPorts.RequestedHostIPs[pair.containerPort+"/"+protocol] = hostIP
+					containerPorts.RequestedLocalPorts[pair.containerPort+"/"+protocol] = binding.LocalPort
+					m.logger.Debugf("Stored port bindings: %s -> %s/%s",
+						net.JoinHostPort(hostIP, binding.LocalPort), pair.containerPort, protocol)
+				}
+				continue
+			}
+
+			for _, pair := range pairs {
+				binding := newPortBinding(hostIP, pair.hostPort, pair.hostPort, pair.containerPort, protocol)
+				if hostBinding.HostIP != "" {
+					// Published on the remote loopback by the create request
+					binding.RemoteHost = RemoteLoopback(hostBinding.HostIP)
+				}
+				if warning, _ := m.checkLocalPort(binding, containerPorts.Bindings); warning != "" {
+					warnings = append(warnings, warning)
+				}
+				containerPorts.Bindings = append(containerPorts.Bindings, binding)
+				m.logger.Debugf("Stored port bindings: %s -> %s/%s",
+					net.JoinHostPort(hostIP, binding.LocalPort), pair.containerPort, protocol)
 			}
-			bindings = append(bindings, binding)
-			m.logger.Debugf("Stored port bindings: %s:%s -> %s/%s",
-				hostPort, port, port, protocol)
 		}
 	}
 
-	if len(bindings) > 0 {
-		m.containers[req] = &ContainerPorts{
-			Bindings: bindings,
+	if len(containerPorts.Bindings) > 0 || len(containerPorts.RequestedHostIPs) > 0 {
+		m.containers[req] = containerPorts
+	}
+	return warnings
+}
+
+// LocalPortLabels returns the labels recording the requested local ports of
+// a container being created whose remote ports are left to Docker, keyed by
+// LocalPortLabelPrefix and the container port. When it is not empty, the host
+// ports of bindings without a HostIp must be cleared from the create request.
+func (m *PortForwardManager) LocalPortLabels(req *http.Request) map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	labels := make(map[string]string)
+	if containerPorts, ok := m.containers[req]; ok {
+		for containerPort, localPort := range containerPorts.RequestedLocalPorts {
+			labels[LocalPortLabelPrefix+containerPort] = localPort
+		}
+	}
+	return labels
+}
+
+// parseLocalPortLabels extracts the requested local ports recorded by
+// LocalPortLabels from the labels of a container
+func parseLocalPortLabels(labels map[string]string) map[string]string {
+	localPorts := make(map[string]string)
+	for label, localPort := range labels {
+		if containerPort, ok := strings.CutPrefix(label, LocalPortLabelPrefix); ok {
+			localPorts[containerPort] = localPort
 		}
 	}
+	return localPorts
 }
 
 // StorePortBindingsEnd stores container id found from the container create request
@@ -115,27 +223,28 @@ func (m *PortForwardManager) StorePortBindingsEnd(req *http.Request, containerID
 		}
 	}
 	delete(m.containers, req)
+	m.storeSocketForwardsEnd(req, containerID)
 }
 
 // StorePortBindingsForContainer stores port bindings directly for a container ID
-func (m *PortForwardManager) StorePortBindingsForContainer(containerID string, hostPorts map[string][]string) {
+func (m *PortForwardManager) StorePortBindingsForContainer(containerID string, meta ContainerMeta, hostPorts map[string][]string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	localPorts := parseLocalPortLabels(meta.Labels)
+	containerPorts := &ContainerPorts{
+		ContainerID:         containerID,
+		Name:                strings.TrimPrefix(meta.Name, "/"),
+		Labels:              meta.Labels,
+		RequestedLocalPorts: localPorts,
+	}
 	bindings := make([]*PortBinding, 0)
 	for containerPort, hostPortList := range hostPorts {
 		if len(hostPortList) == 0 {
 			continue
 		}
 
-		// Extract protocol from container port (e.g., "80/tcp" -> "tcp")
-		parts := strings.Split(containerPort, "/")
-		port := containerPort
-		protocol := "tcp"
-		if len(parts) == 2 {
-			port = parts[0]
-			protocol = parts[1]
-		}
+		port, protocol := splitContainerPort(containerPort)
 
 		// Take all host port bindings
 		for _, hostPort := range hostPortList {
@@ -143,11 +252,9 @@ func (m *PortForwardManager) StorePortBindingsForContainer(containerID string, h
 				continue
 			}
 
-			binding := &PortBinding{
-				HostPort:      hostPort,
-				ContainerPort: port,
-				Protocol:      protocol,
-				StopCh:        make(chan struct{}),
+			binding := newPortBinding(m.defaultHostIP(containerPorts), hostPort, hostPort, port, protocol)
+			if localPort, ok := localPorts[containerPort]; ok {
+				binding.LocalPort = localPort
 			}
 			bindings = append(bindings, binding)
 			m.logger.Debugf("Stored port binding for container %s: %s:%s -> %s/%s",
@@ -156,14 +263,220 @@ func (m *PortForwardManager) StorePortBindingsForContainer(containerID string, h
 	}
 
 	if len(bindings) > 0 {
-		containerPorts := &ContainerPorts{
-			ContainerID: containerID,
-			Bindings:    bindings,
+		containerPorts.Bindings = bindings
+		m.containerPorts[containerID] = containerPorts
+	}
+}
+
+// StorePublishedPorts records the ports Docker actually published for a
+// running container, which includes the ports assigned by `-P` and `-p 80`.
+// Each new port is forwarded on the local port recorded in the container
+// labels by LocalPortLabels, or on the same local port number when it is
+// free, or on a free local port otherwise.
+func (m *PortForwardManager) StorePublishedPorts(containerID string, meta ContainerMeta, hostPorts map[string][]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	containerPorts, exists := m.containerPorts[containerID]
+	if !exists {
+		containerPorts = &ContainerPorts{ContainerID: containerID}
+	}
+	if name := strings.TrimPrefix(meta.Name, "/"); name != "" {
+		containerPorts.Name = name
+	}
+	if meta.Labels != nil {
+		containerPorts.Labels = meta.Labels
+	}
+	if containerPorts.RequestedLocalPorts == nil {
+		containerPorts.RequestedLocalPorts = make(map[string]string)
+	}
+	for containerPort, localPort := range parseLocalPortLabels(meta.Labels) {
+		containerPorts.RequestedLocalPorts[containerPort] = localPort
+	}
+
+	known := make(map[string]bool)
+	for _, binding := range containerPorts.Bindings {
+		known[binding.HostPort+"/"+binding.Protocol] = true
+	}
+
+	for containerPort, hostPortList := range hostPorts {
+		port, protocol := splitContainerPort(containerPort)
+		for _, hostPort := range hostPortList {
+			// IPv4 and IPv6 bindings of the same port are reported separately
+			if hostPort == "" || known[hostPort+"/"+protocol] {
+				continue
+			}
+			known[hostPort+"/"+protocol] = true
+
+			hostIP := m.defaultHostIP(containerPorts)
+			if requested, ok := containerPorts.RequestedHostIPs[containerPort]; ok {
+				hostIP = requested
+			}
+
+			binding := newPortBinding(hostIP, hostPort, hostPort, port, protocol)
+			if localPort, ok := containerPorts.RequestedLocalPorts[containerPort]; ok {
+				// Checked again when the forward is set up
+				binding.LocalPort = localPort
+				containerPorts.Bindings = append(containerPorts.Bindings, binding)
+				m.logger.Debugf("Stored published port for container %s: %s -> %s -> %s/%s",
+					containerID, net.JoinHostPort(hostIP, localPort), hostPort, port, protocol)
+				continue
+			}
+
+			localPort, err := m.allocateLocalPort(protocol, hostIP, hostPort, containerPorts.Bindings)
+			if err != nil {
+				m.logger.Infof("Ignored published port %s/%s: %v", hostPort, protocol, err)
+				continue
+			}
+			binding.LocalPort = localPort
+			if localPort != hostPort {
+				binding.Warning = fmt.Sprintf("remote port %s/%s is forwarded on local port %s", hostPort, protocol, localPort)
+			}
+			containerPorts.Bindings = append(containerPorts.Bindings, binding)
+			m.logger.Debugf("Stored published port for container %s: %s -> %s -> %s/%s",
+				containerID, net.JoinHostPort(hostIP, localPort), hostPort, port, protocol)
 		}
+	}
+
+	if len(containerPorts.Bindings) > 0 {
 		m.containerPorts[containerID] = containerPorts
 	}
 }
 
+// Status returns the port forwards of all containers, sorted by container ID
+// and local port
+func (m *PortForwardManager) Status() []types.PortForwardStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	status := make([]types.PortForwardStatus, 0)
+	for containerID, containerPorts := range m.containerPorts {
+		for _, binding := range containerPorts.Bindings {
+			status = append(status, types.PortForwardStatus{
+				ContainerID:   containerID,
+				LocalAddress:  net.JoinHostPort(binding.HostIP, binding.LocalPort),
+				RemotePort:    binding.HostPort,
+				ContainerPort: binding.ContainerPort + "/" + binding.Protocol,
+				Active:        binding.SessionID != "" || binding.PacketConn != nil,
+				Warning:       binding.Warning,
+			})
+		}
+	}
+
+	sort.Slice(status, func(i, j int) bool {
+		if status[i].ContainerID != status[j].ContainerID {
+			return status[i].ContainerID < status[j].ContainerID
+		}
+		return status[i].LocalAddress < status[j].LocalAddress
+	})
+	return status
+}
+
+// LocalPorts returns the local port of every forward of a container whose
+// local port differs from the remote one, keyed by "<host port>/<protocol>"
+func (m *PortForwardManager) LocalPorts(containerID string) map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	localPorts := make(map[string]string)
+	if containerPorts, exists := m.containerPorts[containerID]; exists {
+		for _, binding := range containerPorts.Bindings {
+			if binding.LocalPort != binding.HostPort {
+				localPorts[binding.HostPort+"/"+binding.Protocol] = binding.LocalPort
+			}
+		}
+	}
+	return localPorts
+}
+
+// allocateLocalPort picks the local port for a remote host port, preferring
+// the same port number and skipping the ports of pending bindings that are
+// not stored yet. The caller must hold the lock.
+func (m *PortForwardManager) allocateLocalPort(protocol string, hostIP string, hostPort string, pending []*PortBinding) (string, error) {
+	inUse := m.localPortsInUse(nil, pending)
+
+	if !inUse[localPortKey(hostIP, hostPort, protocol)] && isLocalPortFree(protocol, hostIP, hostPort) {
+		return hostPort, nil
+	}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		port, err := freeLocalPort(protocol, hostIP)
+		if err != nil {
+			return "", err
+		}
+		if !inUse[localPortKey(hostIP, port, protocol)] {
+			return port, nil
+		}
+	}
+	return "", fmt.Errorf("unable to find a free local port")
+}
+
+// localPortsInUse returns the local addresses of all bindings other than
+// exclude, keyed by localPortKey. The caller must hold the lock.
+func (m *PortForwardManager) localPortsInUse(exclude *PortBinding, pending []*PortBinding) map[string]bool {
+	inUse := make(map[string]bool)
+	add := func(bindings []*PortBinding) {
+		for _, binding := range bindings {
+			if binding != exclude {
+				inUse[localPortKey(binding.HostIP, binding.LocalPort, binding.Protocol)] = true
+			}
+		}
+	}
+
+	for _, containerPorts := range m.containerPorts {
+		add(containerPorts.Bindings)
+	}
+	for _, containerPorts := range m.containers {
+		add(containerPorts.Bindings)
+	}
+	add(pending)
+	return inUse
+}
+
+// localPortKey identifies a local listening address, e.g. "localhost:8080/tcp"
+func localPortKey(host string, port string, protocol string) string {
+	return net.JoinHostPort(host, port) + "/" + protocol
+}
+
+// checkLocalPort makes sure the local port of a binding is free. With
+// RemapPorts a conflicting binding is moved to a free local port, otherwise
+// it keeps its port and cannot be forwarded until the port is released. It
+// returns the warning to report, and false if the binding cannot be
+// forwarded. The caller must hold the lock.
+func (m *PortForwardManager) checkLocalPort(binding *PortBinding, pending []*PortBinding) (string, bool) {
+	inUse := m.localPortsInUse(binding, pending)
+	if !inUse[localPortKey(binding.HostIP, binding.LocalPort, binding.Protocol)] && isLocalPortFree(binding.Protocol, binding.HostIP, binding.LocalPort) {
+		if binding.LocalPort == binding.HostPort {
+			binding.Warning = ""
+		}
+		return "", true
+	}
+
+	address := net.JoinHostPort(binding.HostIP, binding.LocalPort)
+	if !m.transportConfig.RemapPorts {
+		binding.Warning = fmt.Sprintf("local port %s/%s is already in use, container port %s/%s is not forwarded until it is released (start the daemon with --remap-ports to use a free port instead)",
+			address, binding.Protocol, binding.ContainerPort, binding.Protocol)
+		m.logger.Warn(binding.Warning)
+		return binding.Warning, false
+	}
+
+	localPort, err := m.allocateLocalPort(binding.Protocol, binding.HostIP, binding.HostPort, pending)
+	if err == nil && localPort == binding.LocalPort {
+		err = fmt.Errorf("unable to find a free local port")
+	}
+	if err != nil {
+		binding.Warning = fmt.Sprintf("local port %s/%s is already in use and could not be remapped: %v", address, binding.Protocol, err)
+		m.logger.Warn(binding.Warning)
+		return binding.Warning, false
+	}
+
+	binding.LocalPort = localPort
+	binding.Warning = fmt.Sprintf("local port %s/%s is already in use, container port %s/%s is forwarded on %s instead",
+		address, binding.Protocol, binding.ContainerPort, binding.Protocol, net.JoinHostPort(binding.HostIP, localPort))
+	m.logger.Warn(binding.Warning)
+	return binding.Warning, true
+}
+
 // SetupForwards sets up SSH port forwards for a container
 func (m *PortForwardManager) SetupForwards(containerID string, promptIdentifier string) error {
 	m.mu.Lock()
@@ -177,9 +490,25 @@ func (m *PortForwardManager) SetupForwards(containerID string, promptIdentifier
 	}
 
 	for _, binding := range containerPorts.Bindings {
+		if binding.SessionID != "" || binding.PacketConn != nil {
+			// Already forwarded
+			continue
+		}
+		// The port may have been taken since the container was created
+		if _, ok := m.checkLocalPort(binding, nil); !ok {
+			continue
+		}
+		if binding.Protocol == "udp" {
+			if err := m.setupUDPForward(binding); err != nil {
+				m.logger.Infof("Failed to setup UDP port forward %s: %v", binding.LocalPort, err)
+				binding.Warning = fmt.Sprintf("unable to forward local port %s/udp: %v", binding.LocalPort, err)
+			}
+			continue
+		}
 		sessionID, err := m.setupSingleForward(containerID, binding, promptIdentifier)
 		if err != nil {
-			m.logger.Infof("Failed to setup port forward %s: %v", binding.HostPort, err)
+			m.logger.Infof("Failed to setup port forward %s: %v", binding.LocalPort, err)
+			binding.Warning = fmt.Sprintf("unable to forward local port %s/tcp: %v", binding.LocalPort, err)
 			// Continue with other ports even if one fails
 		}
 		binding.SessionID = sessionID
@@ -273,9 +602,65 @@ func loadAndValidateGlobalForwardingConfiguration(path string) (*forwarding.Conf
 	return configuration, nil
 }
 
-// setupSingleForward sets up a single SSH port forward
+// setupSingleForward sets up a single SSH port forward for a tcp binding
 func (m *PortForwardManager) setupSingleForward(containerID string, binding *PortBinding, promptIdentifier string) (string, error) {
-	pfCreateConfiguration.name = fmt.Sprintf("forward-%s-%s-%s", containerID[:8], "tcp", binding.HostPort) // mutagen does not support udp at the moment
+	prefix := "forward"
+	if binding.Auto {
+		prefix = "auto"
+	}
+	name := fmt.Sprintf("%s-%s-%s-%s", prefix, containerID[:8], "tcp", binding.HostPort) // udp is handled by setupUDPForward
+	remoteHost := binding.RemoteHost
+	if remoteHost == "" {
+		remoteHost = localhostAddress
+	}
+
+	source, err := url.Parse(localForwardEndpoint(binding.HostIP, binding.LocalPort), url.Kind_Forwarding, true)
+	if err != nil {
+		return "", fmt.Errorf("invalid forwarding source: %w", err)
+	}
+	destination, err := m.remoteForwardURL("tcp:" + net.JoinHostPort(remoteHost, binding.HostPort))
+	if err != nil {
+		return "", fmt.Errorf("invalid forwarding destination: %w", err)
+	}
+
+	resetForwardCreateConfiguration(name)
+	labels := map[string]string{"container-id": compressContainerID(containerID)}
+	session, err := m.createForwardSession(source, destination, labels, promptIdentifier)
+	if err != nil {
+		return "", err
+	}
+
+	m.logger.Infof("Created port forwarding session %s on port %s", session, binding.LocalPort)
+	return session, nil
+}
+
+// remoteForwardURL builds the URL of a forwarding endpoint on the remote
+// host, e.g. "tcp:localhost:8080", over the configured transport
+func (m *PortForwardManager) remoteForwardURL(endpoint string) (*url.URL, error) {
+	if m.transportConfig.TransportType == types.TransportTSTunnel {
+		forwardURL := fmt.Sprintf("ts://%s/%s?a=a", m.transportConfig.TSTunnelServer, endpoint)
+		if m.transportConfig.TSTunnelCertFile != "" && m.transportConfig.TSTunnelKeyFile != "" {
+			forwardURL = forwardURL + "&cert=" + neturl.QueryEscape(m.transportConfig.TSTunnelCertFile) + "&key=" + neturl.QueryEscape(m.transportConfig.TSTunnelKeyFile)
+		}
+
+		if m.transportConfig.TSTunnelCAFile != "" {
+			forwardURL = forwardURL + "&ca=" + neturl.QueryEscape(m.transportConfig.TSTunnelCAFile)
+		}
+		if m.transportConfig.TSInsecure {
+			forwardURL = forwardURL + "&insecure=true"
+		}
+		return ts_tunnel.ParseTSTunnelURL(forwardURL, url.Kind_Forwarding)
+	}
+
+	// Default to SSH: user@host:tcp:localhost:<port>
+	forwardURL := fmt.Sprintf("%s@%s:%s", m.transportConfig.SSHUser, m.transportConfig.SSHHost, endpoint)
+	return url.Parse(forwardURL, url.Kind_Forwarding, true)
+}
+
+// resetForwardCreateConfiguration resets pfCreateConfiguration to the
+// defaults for a new session named name
+func resetForwardCreateConfiguration(name string) {
+	pfCreateConfiguration.name = name
 	pfCreateConfiguration.labels = nil
 	pfCreateConfiguration.paused = false
 	pfCreateConfiguration.noGlobalConfiguration = false
@@ -296,44 +681,12 @@ func (m *PortForwardManager) setupSingleForward(containerID string, binding *Por
 	pfCreateConfiguration.socketPermissionMode = ""
 	pfCreateConfiguration.socketPermissionModeSource = ""
 	pfCreateConfiguration.socketPermissionModeDestination = ""
+}
 
-	forwardSource := fmt.Sprintf("tcp:localhost:%s", binding.HostPort)
-
-	var destination *url.URL
-	var err error
-
-	// Build destination URL based on transport type
-	if m.transportConfig.TransportType == types.TransportTSTunnel {
-		forwardDest := fmt.Sprintf("ts://%s/tcp:localhost:%s?a=a",
-			m.transportConfig.TSTunnelServer,
-			binding.HostPort,
-		)
-		if m.transportConfig.TSTunnelCertFile != "" && m.transportConfig.TSTunnelKeyFile != "" {
-			forwardDest = forwardDest + "&cert=" + neturl.QueryEscape(m.transportConfig.TSTunnelCertFile) + "&key=" + neturl.QueryEscape(m.transportConfig.TSTunnelKeyFile)
-		}
-
-		if m.transportConfig.TSTunnelCAFile != "" {
-			forwardDest = forwardDest + "&ca=" + neturl.QueryEscape(m.transportConfig.TSTunnelCAFile)
-		}
-		if m.transportConfig.TSInsecure {
-			forwardDest = forwardDest + "&insecure=true"
-		}
-		destination, err = ts_tunnel.ParseTSTunnelURL(forwardDest, url.Kind_Forwarding)
-	} else {
-		// Default to SSH: user@host:port:tcp:localhost:<port>
-		forwardDest := fmt.Sprintf("%s@%s:tcp:localhost:%s",
-			m.transportConfig.SSHUser, m.transportConfig.SSHHost, binding.HostPort)
-		destination, err = url.Parse(forwardDest, url.Kind_Forwarding, true)
-	}
-	if err != nil {
-		return "", fmt.Errorf("invalid forwarding destination: %w", err)
-	}
-
-	source, err := url.Parse(forwardSource, url.Kind_Forwarding, true)
-	if err != nil {
-		return "", fmt.Errorf("invalid forwarding source: %w", err)
-	}
-
+// createForwardSession creates a mutagen forwarding session from source to
+// destination as configured by pfCreateConfiguration, merging the global
+// forwarding configuration
+func (m *PortForwardManager) createForwardSession(source *url.URL, destination *url.URL, sessionLabels map[string]string, promptIdentifier string) (string, error) {
 	if err := selection.EnsureNameValid(pfCreateConfiguration.name); err != nil {
 		return "", fmt.Errorf("invalid session name: %w", err)
 	}
@@ -353,7 +706,9 @@ func (m *PortForwardManager) setupSingleForward(containerID string, binding *Por
 		}
 		labels[key] = value
 	}
-	labels["container-id"] = compressContainerID(containerID)
+	for key, value := range sessionLabels {
+		labels[key] = value
+	}
 
 	// Create a default session configuration that will form the basis of our
 	// cumulative configuration.
@@ -515,8 +870,6 @@ func (m *PortForwardManager) setupSingleForward(containerID string, binding *Por
 	if err != nil {
 		return "", err
 	}
-
-	m.logger.Infof("Created port forwarding session %s on port %s", session, binding.HostPort)
 	return session, nil
 }
 
@@ -531,11 +884,17 @@ func (m *PortForwardManager) TeardownForwards(containerID string) {
 			close(binding.StopCh)
 			if binding.Listener != nil {
 				_ = binding.Listener.Close()
-				m.logger.Infof("✗ Closed port forward: localhost:%s", binding.HostPort)
+				m.logger.Infof("✗ Closed port forward: %s", net.JoinHostPort(binding.HostIP, binding.LocalPort))
+			}
+			if binding.PacketConn != nil {
+				_ = binding.PacketConn.Close()
+				m.logger.Infof("✗ Closed UDP port forward: %s", net.JoinHostPort(binding.HostIP, binding.LocalPort))
 			}
 		}
 		delete(m.containerPorts, containerID)
 	}
+	m.releaseReverseForwards(containerID)
+	m.releaseSocketForwards(containerID)
 
 	selected := &selection.Selection{
 		All:            false,
@@ -592,9 +951,14 @@ func (m *PortForwardManager) TeardownAll() {
 			if binding.Listener != nil {
 				binding.Listener.Close()
 			}
+			if binding.PacketConn != nil {
+				binding.PacketConn.Close()
+			}
 		}
 		delete(m.containerPorts, containerID)
 	}
+	m.reverseForwards = make(map[string]*ReverseForward)
+	m.socketForwards = make(map[string]*SocketForward)
 
 	// Terminate all forwarding sessions
 	selected := &selection.Selection{
@@ -607,3 +971,173 @@ func (m *PortForwardManager) TeardownAll() {
 		m.logger.Infof("Error terminating all port forwards: %s", err)
 	}
 }
+
+// listenAddress returns the local address to listen on for a requested
+// HostIp. Loopback addresses are honored, other addresses such as 0.0.0.0
+// expose the port to the network and require AllowPublicPorts.
+func (m *PortForwardManager) listenAddress(hostIP string) string {
+	if hostIP == "" {
+		return localhostAddress
+	}
+
+	ip := net.ParseIP(hostIP)
+	if ip == nil {
+		m.logger.Infof("Ignored invalid HostIp %s, listening on %s", hostIP, localhostAddress)
+		return localhostAddress
+	}
+	if ip.IsLoopback() || m.transportConfig.AllowPublicPorts {
+		return hostIP
+	}
+
+	m.logger.Warnf("Listening on %s instead of %s, start the daemon with --allow-public-ports to expose ports to the network",
+		localhostAddress, hostIP)
+	return localhostAddress
+}
+
+// portPair is a host port and the container port it is published for
+type portPair struct {
+	hostPort      string
+	containerPort string
+}
+
+// expandPortRange expands a container port and host port specification into
+// individual port pairs, e.g. "8000-8002" and "9000-9002" into 9000->8000,
+// 9001->8001 and 9002->8002. It returns true instead if Docker assigns the
+// host port, i.e. when it is empty or a range for a single container port.
+func expandPortRange(containerPort string, hostPort string) ([]portPair, bool, error) {
+	containerStart, containerEnd, err := parsePortRange(containerPort)
+	if err != nil {
+		return nil, false, err
+	}
+	if hostPort == "" {
+		return nil, true, nil
+	}
+	hostStart, hostEnd, err := parsePortRange(hostPort)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if containerStart == containerEnd {
+		if hostStart != hostEnd {
+			return nil, true, nil
+		}
+		return []portPair{{hostPort: hostPort, containerPort: containerPort}}, false, nil
+	}
+
+	if hostEnd-hostStart != containerEnd-containerStart {
+		return nil, false, fmt.Errorf("host port range %s does not match container port range %s", hostPort, containerPort)
+	}
+
+	pairs := make([]portPair, 0, containerEnd-containerStart+1)
+	for offset := 0; offset <= containerEnd-containerStart; offset++ {
+		pairs = append(pairs, portPair{
+			hostPort:      strconv.Itoa(hostStart + offset),
+			containerPort: strconv.Itoa(containerStart + offset),
+		})
+	}
+	return pairs, false, nil
+}
+
+// parsePortRange parses a port ("80") or a port range ("8000-8010")
+func parsePortRange(ports string) (int, int, error) {
+	startStr, endStr, isRange := strings.Cut(ports, "-")
+	if !isRange {
+		endStr = startStr
+	}
+
+	start, err := strconv.ParseUint(startStr, 10, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port %q", ports)
+	}
+	end, err := strconv.ParseUint(endStr, 10, 16)
+	if err != nil || end < start {
+		return 0, 0, fmt.Errorf("invalid port range %q", ports)
+	}
+	return int(start), int(end), nil
+}
+
+// localForwardEndpoint builds the mutagen endpoint listening on host:port,
+// e.g. "tcp:localhost:8080" or "tcp6:[::1]:8080"
+func localForwardEndpoint(host string, port string) string {
+	protocol := "tcp"
+	if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+		protocol = "tcp6"
+	}
+	return fmt.Sprintf("%s:%s", protocol, net.JoinHostPort(host, port))
+}
+
+// RemoteLoopback returns the loopback address of the remote host in the
+// family of a requested HostIp. Ports with a HostIp are published there, only
+// reachable by the forward instead of on the public interfaces of the remote
+// host.
+func RemoteLoopback(hostIP string) string {
+	if ip := net.ParseIP(hostIP); ip != nil && ip.To4() == nil {
+		return "::1"
+	}
+	return "127.0.0.1"
+}
+
+// newPortBinding creates a port binding forwarding hostIP:localPort to hostPort
+func newPortBinding(hostIP, hostPort, localPort, containerPort, protocol string) *PortBinding {
+	return &PortBinding{
+		HostIP:        hostIP,
+		HostPort:      hostPort,
+		LocalPort:     localPort,
+		ContainerPort: containerPort,
+		Protocol:      protocol,
+		StopCh:        make(chan struct{}),
+	}
+}
+
+// splitContainerPort extracts the protocol from a container port (e.g., "80/tcp" -> "80", "tcp")
+func splitContainerPort(containerPort string) (string, string) {
+	parts := strings.Split(containerPort, "/")
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return containerPort, "tcp"
+}
+
+// isLocalPortFree checks if a local port can be listened on
+func isLocalPortFree(protocol string, host string, port string) bool {
+	address := net.JoinHostPort(host, port)
+	if protocol == "udp" {
+		conn, err := net.ListenPacket("udp", address)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return false
+	}
+	listener.Close()
+	return true
+}
+
+// freeLocalPort asks the system for a free local port
+func freeLocalPort(protocol string, host string) (string, error) {
+	address := net.JoinHostPort(host, "0")
+	var bound net.Addr
+	if protocol == "udp" {
+		conn, err := net.ListenPacket("udp", address)
+		if err != nil {
+			return "", err
+		}
+		bound = conn.LocalAddr()
+		conn.Close()
+	} else {
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return "", err
+		}
+		bound = listener.Addr()
+		listener.Close()
+	}
+
+	_, port, err := net.SplitHostPort(bound.String())
+	return port, err
+}
@@ -0,0 +1,104 @@
+package mutagen_bridge
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	syncmodels "github.com/mutagen-io/mutagen/pkg/api/models/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/encoding"
+)
+
+// ProjectConfigFile is the per-project configuration file, found by walking
+// up from the host path of a bind mount
+const ProjectConfigFile = ".tinyscale.yml"
+
+// ProjectConfig is the content of ProjectConfigFile, e.g.
+//
+//	sync:
+//	  mode: two-way-safe
+//	  ignore:
+//	    vcs: true
+//	    paths: [node_modules, dist]
+//	  symlink:
+//	    mode: posix-raw
+//	  permissions:
+//	    defaultFileMode: 0644
+//	  maxStagingFileSize: 100MB
+//	  compression:
+//	    algorithm: deflate
+//	dockerignore: false
+//	dependencies: [node_modules]
+type ProjectConfig struct {
+	// Sync has the format of the sync defaults of the mutagen global
+	// configuration and is merged on top of them
+	Sync syncmodels.Configuration `yaml:"sync"`
+
+	// DockerIgnore set to false stops converting the .dockerignore of synced
+	// directories into ignores
+	DockerIgnore *bool `yaml:"dockerignore"`
+
+	// Dependencies are the subdirectories of synced directories kept on
+	// remote volumes instead of being synced, replacing the ones of the daemon
+	Dependencies []string `yaml:"dependencies"`
+}
+
+// findProjectConfig returns the ProjectConfigFile closest to hostPath, in
+// hostPath itself or one of its parents, or "" if there is none
+func findProjectConfig(hostPath string) string {
+	dir := filepath.Clean(hostPath)
+	for {
+		candidate := filepath.Join(dir, ProjectConfigFile)
+		if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() {
+			return candidate
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// loadProjectConfig loads and validates the project file hostPath belongs
+// to. It returns a nil configuration when there is no project file.
+func loadProjectConfig(hostPath string) (*ProjectConfig, string, error) {
+	path := findProjectConfig(hostPath)
+	if path == "" {
+		return nil, "", nil
+	}
+
+	var project ProjectConfig
+	if err := encoding.LoadAndUnmarshalYAML(path, &project); err != nil {
+		return nil, path, fmt.Errorf("unable to load %s: %w", path, err)
+	}
+	if err := project.Sync.ToInternal().EnsureValid(false); err != nil {
+		return nil, path, fmt.Errorf("invalid sync configuration in %s: %w", path, err)
+	}
+	return &project, path, nil
+}
+
+// dockerIgnoreEnabled returns whether the .dockerignore of synced
+// directories is honored, which is the default
+func (p *ProjectConfig) dockerIgnoreEnabled() bool {
+	return p == nil || p.DockerIgnore == nil || *p.DockerIgnore
+}
+
+// ProjectConfigWarnings validates the project files of the mounts of a
+// container being created, the invalid ones are ignored when syncing
+func (m *FileSyncManager) ProjectConfigWarnings(mounts *ContainerMounts) []string {
+	var warnings []string
+	seen := make(map[string]bool)
+	for _, mount := range mounts.Mounts {
+		if mount.Socket {
+			continue
+		}
+		_, path, err := loadProjectConfig(mount.HostPath)
+		if err == nil || seen[path] {
+			continue
+		}
+		seen[path] = true
+		warnings = append(warnings, fmt.Sprintf("ignored project file: %v", err))
+	}
+	return warnings
+}
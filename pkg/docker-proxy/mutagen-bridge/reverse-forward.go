@@ -0,0 +1,242 @@
+package mutagen_bridge
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mutagen-io/mutagen/pkg/selection"
+	"github.com/mutagen-io/mutagen/pkg/url"
+	"github.com/teamycloud/tsctl/pkg/docker-proxy/types"
+)
+
+const (
+	// ReversePortsLabel lists the remote ports forwarded back to local ports,
+	// e.g. "tinyscale.reverse-ports=9000,9229:19229" forwards remote port 9000
+	// to local port 9000 and remote port 9229 to local port 19229
+	ReversePortsLabel = "tinyscale.reverse-ports"
+
+	// hostGatewayName is the hostname containers reach the host with
+	hostGatewayName = "host.docker.internal"
+	// hostGatewayValue makes Docker resolve an extra host to the host gateway
+	hostGatewayValue = "host-gateway"
+)
+
+// ReverseForward is a forward from a port on the remote host, reachable from
+// containers as host.docker.internal, to a local port. Containers asking for
+// the same remote port share the forward.
+type ReverseForward struct {
+	RemotePort string
+	LocalPort  string
+	SessionID  string
+	Warning    string          // Forwarding failure, reported in the daemon status
+	Containers map[string]bool // IDs of the containers using the forward
+}
+
+// ReverseForwardRequest checks the labels and extra hosts of a container
+// create request. A container with `host.docker.internal:host-gateway` in its
+// extra hosts and no ReversePortsLabel gets the ReversePorts of the daemon in
+// the returned labels. A container with ReversePortsLabel and no
+// host.docker.internal extra host needs the host-gateway one added.
+func (m *PortForwardManager) ReverseForwardRequest(labels map[string]string, extraHosts []string) (map[string]string, bool) {
+	spec := labels[ReversePortsLabel]
+
+	hasGateway := false
+	hasHost := false
+	for _, extraHost := range extraHosts {
+		host, ip, ok := strings.Cut(extraHost, ":")
+		if !ok {
+			host, ip, ok = strings.Cut(extraHost, "=")
+		}
+		if !ok || host != hostGatewayName {
+			continue
+		}
+		hasHost = true
+		if ip == hostGatewayValue {
+			hasGateway = true
+		}
+	}
+
+	if spec == "" && hasGateway && m.transportConfig.ReversePorts != "" {
+		return map[string]string{ReversePortsLabel: m.transportConfig.ReversePorts}, false
+	}
+	return nil, spec != "" && !hasHost
+}
+
+// parseReversePorts parses the value of ReversePortsLabel into remote port
+// -> local port
+func parseReversePorts(spec string) (map[string]string, error) {
+	ports := make(map[string]string)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		remotePort, localPort, ok := strings.Cut(item, ":")
+		if !ok {
+			localPort = remotePort
+		}
+		for _, port := range []string{remotePort, localPort} {
+			if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+				return nil, fmt.Errorf("invalid port %q in %q", port, item)
+			}
+		}
+		ports[remotePort] = localPort
+	}
+	return ports, nil
+}
+
+// SetupReverseForwards sets up the reverse forwards listed in the
+// ReversePortsLabel of a running container
+func (m *PortForwardManager) SetupReverseForwards(containerID string, labels map[string]string, promptIdentifier string) {
+	spec := labels[ReversePortsLabel]
+	if spec == "" {
+		return
+	}
+	ports, err := parseReversePorts(spec)
+	if err != nil {
+		m.logger.Warnf("Ignored label %s of container %s: %v", ReversePortsLabel, containerID, err)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for remotePort, localPort := range ports {
+		forward, exists := m.reverseForwards[remotePort]
+		if !exists {
+			forward = &ReverseForward{
+				RemotePort: remotePort,
+				LocalPort:  localPort,
+				Containers: make(map[string]bool),
+			}
+			m.reverseForwards[remotePort] = forward
+		} else if forward.LocalPort != localPort {
+			m.logger.Warnf("Remote port %s is already forwarded to local port %s, ignored local port %s of container %s",
+				remotePort, forward.LocalPort, localPort, containerID)
+		}
+		forward.Containers[containerID] = true
+
+		if forward.SessionID != "" {
+			continue
+		}
+		sessionID, err := m.setupReverseForward(forward, promptIdentifier)
+		if err != nil {
+			m.logger.Infof("Failed to setup reverse port forward %s: %v", remotePort, err)
+			forward.Warning = fmt.Sprintf("unable to forward remote port %s to local port %s: %v", remotePort, localPort, err)
+			continue
+		}
+		forward.SessionID = sessionID
+		forward.Warning = ""
+	}
+}
+
+// setupReverseForward creates the mutagen session of a reverse forward,
+// listening on the remote host and connecting to the local port. A session
+// left by a previous daemon run for the same local port is reused, the ones
+// for other local ports are terminated.
+func (m *PortForwardManager) setupReverseForward(forward *ReverseForward, promptIdentifier string) (string, error) {
+	sessionLabels := map[string]string{
+		"reverse-port":       forward.RemotePort,
+		"reverse-local-port": forward.LocalPort,
+	}
+
+	_, states, err := m.mutagenForwardMgr.List(context.Background(), &selection.Selection{
+		LabelSelector: "reverse-port=" + forward.RemotePort,
+	}, 0)
+	if err == nil {
+		reused := ""
+		for _, state := range states {
+			if reused == "" && state.Session.Labels["reverse-local-port"] == forward.LocalPort {
+				reused = state.Session.Identifier
+				continue
+			}
+			if err := m.terminateForwardSession(state.Session.Identifier); err != nil {
+				m.logger.Infof("Error terminating stale reverse port forward %s: %s", forward.RemotePort, err)
+			}
+		}
+		if reused != "" {
+			return reused, nil
+		}
+	}
+
+	listenAddress := net.JoinHostPort(m.transportConfig.ReverseListenAddr, forward.RemotePort)
+	source, err := m.remoteForwardURL("tcp:" + listenAddress)
+	if err != nil {
+		return "", fmt.Errorf("invalid forwarding source: %w", err)
+	}
+	destination, err := url.Parse(localForwardEndpoint(localhostAddress, forward.LocalPort), url.Kind_Forwarding, true)
+	if err != nil {
+		return "", fmt.Errorf("invalid forwarding destination: %w", err)
+	}
+
+	resetForwardCreateConfiguration(fmt.Sprintf("reverse-%s-%s", "tcp", forward.RemotePort))
+	session, err := m.createForwardSession(source, destination, sessionLabels, promptIdentifier)
+	if err != nil {
+		return "", err
+	}
+
+	m.logger.Infof("Created reverse port forwarding session %s from remote %s to local port %s", session, listenAddress, forward.LocalPort)
+	return session, nil
+}
+
+// releaseReverseForwards removes a container from the reverse forwards it
+// uses and terminates the forwards no container uses anymore. The caller must
+// hold the lock.
+func (m *PortForwardManager) releaseReverseForwards(containerID string) {
+	for remotePort, forward := range m.reverseForwards {
+		if !forward.Containers[containerID] {
+			continue
+		}
+		delete(forward.Containers, containerID)
+		if len(forward.Containers) > 0 {
+			continue
+		}
+		delete(m.reverseForwards, remotePort)
+
+		if forward.SessionID == "" {
+			continue
+		}
+		if err := m.terminateForwardSession(forward.SessionID); err != nil {
+			m.logger.Infof("Error terminating reverse port forward %s: %s", remotePort, err)
+			continue
+		}
+		m.logger.Infof("✗ Closed reverse port forward: remote %s -> local %s", remotePort, forward.LocalPort)
+	}
+}
+
+// ReverseStatus returns the reverse forwards, sorted by remote port
+func (m *PortForwardManager) ReverseStatus() []types.ReverseForwardStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	forwards := make([]*ReverseForward, 0, len(m.reverseForwards))
+	for _, forward := range m.reverseForwards {
+		forwards = append(forwards, forward)
+	}
+	sort.Slice(forwards, func(i, j int) bool {
+		a, _ := strconv.Atoi(forwards[i].RemotePort)
+		b, _ := strconv.Atoi(forwards[j].RemotePort)
+		return a < b
+	})
+
+	status := make([]types.ReverseForwardStatus, 0, len(forwards))
+	for _, forward := range forwards {
+		containers := make([]string, 0, len(forward.Containers))
+		for containerID := range forward.Containers {
+			containers = append(containers, containerID)
+		}
+		sort.Strings(containers)
+		status = append(status, types.ReverseForwardStatus{
+			RemoteAddress: net.JoinHostPort(m.transportConfig.ReverseListenAddr, forward.RemotePort),
+			LocalPort:     forward.LocalPort,
+			Containers:    containers,
+			Active:        forward.SessionID != "",
+			Warning:       forward.Warning,
+		})
+	}
+	return status
+}
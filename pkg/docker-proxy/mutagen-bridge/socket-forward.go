@@ -0,0 +1,178 @@
+package mutagen_bridge
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+
+	"github.com/mutagen-io/mutagen/pkg/selection"
+	"github.com/mutagen-io/mutagen/pkg/url"
+)
+
+// SocketForward forwards a Unix socket on the remote host, at the sync path
+// of a bind mounted local socket such as $SSH_AUTH_SOCK, back to the local
+// socket. Containers mounting the same socket share the forward.
+type SocketForward struct {
+	HostPath   string // Local socket (e.g., "/tmp/ssh-XXXX/agent.123")
+	RemotePath string // Socket listened on on the remote host, the bind source of the container
+	SessionID  string
+	Containers map[string]bool // IDs of the containers using the forward
+}
+
+// StoreSocketForwardsStart sets up the forwards of the sockets bind mounted
+// by a container being created. The remote socket has to exist before the
+// create request is forwarded, Docker would create a directory in its place
+// otherwise.
+func (m *PortForwardManager) StoreSocketForwardsStart(req *http.Request, hostPaths []string, promptIdentifier string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pendingSockets[req] = hostPaths
+	for _, hostPath := range hostPaths {
+		m.setupSocketForward(hostPath, promptIdentifier)
+	}
+}
+
+// storeSocketForwardsEnd records the container ID found from the container
+// create response, forwards of failed creates are torn down. The caller must
+// hold the lock.
+func (m *PortForwardManager) storeSocketForwardsEnd(req *http.Request, containerID string) {
+	hostPaths, ok := m.pendingSockets[req]
+	if !ok {
+		return
+	}
+	delete(m.pendingSockets, req)
+
+	if containerID == "" {
+		m.releaseSocketForwards("")
+		return
+	}
+	m.containerSockets[containerID] = hostPaths
+	for _, hostPath := range hostPaths {
+		if forward, exists := m.socketForwards[hostPath]; exists {
+			forward.Containers[containerID] = true
+		}
+	}
+}
+
+// StoreSocketForwardsForContainer records the sockets bind mounted by an
+// existing container
+func (m *PortForwardManager) StoreSocketForwardsForContainer(containerID string, hostPaths []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(hostPaths) > 0 {
+		m.containerSockets[containerID] = hostPaths
+	}
+}
+
+// SetupSocketForwards sets up the socket forwards of a container, it is
+// called before the start request is forwarded so that restarted containers
+// find their sockets
+func (m *PortForwardManager) SetupSocketForwards(containerID string, promptIdentifier string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, hostPath := range m.containerSockets[containerID] {
+		if forward := m.setupSocketForward(hostPath, promptIdentifier); forward != nil {
+			forward.Containers[containerID] = true
+		}
+	}
+}
+
+// RemoveSocketForwards forgets the sockets of a removed container
+func (m *PortForwardManager) RemoveSocketForwards(containerID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.containerSockets, containerID)
+	m.releaseSocketForwards(containerID)
+}
+
+// setupSocketForward creates the forward of a local socket unless it exists.
+// A session left by a previous daemon run is reused. The caller must hold the
+// lock.
+func (m *PortForwardManager) setupSocketForward(hostPath string, promptIdentifier string) *SocketForward {
+	if forward, exists := m.socketForwards[hostPath]; exists {
+		return forward
+	}
+
+	forward := &SocketForward{
+		HostPath:   hostPath,
+		RemotePath: RemotePath(hostPath),
+		Containers: make(map[string]bool),
+	}
+	sessionID, err := m.createSocketForwardSession(forward, promptIdentifier)
+	if err != nil {
+		m.logger.Warnf("Failed to forward socket %s: %v", hostPath, err)
+		return nil
+	}
+	forward.SessionID = sessionID
+	m.socketForwards[hostPath] = forward
+	return forward
+}
+
+// createSocketForwardSession creates the mutagen session of a socket forward,
+// listening on the remote socket and connecting to the local one
+func (m *PortForwardManager) createSocketForwardSession(forward *SocketForward, promptIdentifier string) (string, error) {
+	// Paths are not valid label values
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(forward.HostPath))
+	key := fmt.Sprintf("%08x", hash.Sum32())
+	sessionLabels := map[string]string{"socket-forward": key}
+
+	_, states, err := m.mutagenForwardMgr.List(context.Background(), &selection.Selection{
+		LabelSelector: "socket-forward=" + key,
+	}, 0)
+	if err == nil && len(states) > 0 {
+		return states[0].Session.Identifier, nil
+	}
+
+	source, err := m.remoteForwardURL("unix:" + forward.RemotePath)
+	if err != nil {
+		return "", fmt.Errorf("invalid forwarding source: %w", err)
+	}
+	destination, err := url.Parse("unix:"+forward.HostPath, url.Kind_Forwarding, true)
+	if err != nil {
+		return "", fmt.Errorf("invalid forwarding destination: %w", err)
+	}
+
+	resetForwardCreateConfiguration("socket-" + key)
+	// A socket left behind by a previous session would block the listener
+	pfCreateConfiguration.socketOverwriteModeSource = "overwrite"
+	session, err := m.createForwardSession(source, destination, sessionLabels, promptIdentifier)
+	if err != nil {
+		return "", err
+	}
+
+	m.logger.Infof("Created socket forwarding session %s from remote %s to local %s", session, forward.RemotePath, forward.HostPath)
+	return session, nil
+}
+
+// releaseSocketForwards removes a container from the socket forwards it uses
+// and terminates the forwards no container uses anymore, including the ones
+// of containers still being created when containerID is empty. The caller
+// must hold the lock.
+func (m *PortForwardManager) releaseSocketForwards(containerID string) {
+	pending := make(map[string]bool)
+	for _, hostPaths := range m.pendingSockets {
+		for _, hostPath := range hostPaths {
+			pending[hostPath] = true
+		}
+	}
+
+	for hostPath, forward := range m.socketForwards {
+		delete(forward.Containers, containerID)
+		if len(forward.Containers) > 0 || pending[hostPath] {
+			continue
+		}
+		delete(m.socketForwards, hostPath)
+
+		if err := m.terminateForwardSession(forward.SessionID); err != nil {
+			m.logger.Infof("Error terminating socket forward %s: %s", hostPath, err)
+			continue
+		}
+		m.logger.Infof("✗ Closed socket forward: remote %s -> local %s", forward.RemotePath, hostPath)
+	}
+}
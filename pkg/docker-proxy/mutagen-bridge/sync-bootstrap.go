@@ -0,0 +1,256 @@
+package mutagen_bridge
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/mutagen-io/mutagen/pkg/filesystem"
+	synchronizationsvc "github.com/mutagen-io/mutagen/pkg/service/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core/ignore"
+	dockerignore "github.com/mutagen-io/mutagen/pkg/synchronization/core/ignore/docker"
+	mutagenignore "github.com/mutagen-io/mutagen/pkg/synchronization/core/ignore/mutagen"
+)
+
+// syncBootstrapPath is the guest endpoint extracting a gzip compressed tar
+// stream into a new or empty directory
+const syncBootstrapPath = "/tinyscale/v1/host-exec/extract"
+
+// syncBootstrapTimeout bounds a bulk upload, mutagen syncs what is left
+const syncBootstrapTimeout = 30 * time.Minute
+
+// guestExtractRequest is the payload of syncBootstrapPath
+type guestExtractRequest struct {
+	Path          string `json:"path"`
+	FileMode      uint32 `json:"fileMode"`
+	DirectoryMode uint32 `json:"directoryMode"`
+}
+
+// guestExtractResult is sent back by the guest once the stream is extracted
+type guestExtractResult struct {
+	Entries int    `json:"entries"`
+	Bytes   int64  `json:"bytes"`
+	Error   string `json:"error"`
+}
+
+// SetGuestDialer sets the dialer used to reach the guest agent, which
+// extracts the bulk uploads of synced directories
+func (m *FileSyncManager) SetGuestDialer(dialer GuestDialer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.guestDialer = dialer
+}
+
+// bootstrapSync uploads the synced directory of a session about to be created
+// to its remote path as a single compressed stream, which is much faster than
+// the first scan and staging of mutagen for large directories. Mutagen then
+// only reconciles the differences. The guest refuses remote directories that
+// are not empty, and any failure leaves the whole sync to mutagen. The caller
+// must not hold the lock, the upload may take minutes.
+func (m *FileSyncManager) bootstrapSync(mount *BindMount, specification *synchronizationsvc.CreationSpecification) {
+	m.mu.RLock()
+	dialer := m.guestDialer
+	m.mu.RUnlock()
+	if dialer == nil || mount.File || mount.Sync.Mode == SyncModeRemoteToLocal {
+		return
+	}
+
+	ignorer, err := syncIgnorer(specification.Configuration)
+	if err != nil {
+		m.logger.Warnf("Skipped bulk upload of %s: %v", mount.HostPath, err)
+		return
+	}
+
+	stream, err := dialer(syncBootstrapPath, &guestExtractRequest{
+		Path: mount.RemotePath,
+		FileMode: betaDefaultMode(specification.ConfigurationBeta.DefaultFileMode,
+			specification.Configuration.DefaultFileMode, synchronization.DefaultVersion.DefaultFileMode()),
+		DirectoryMode: betaDefaultMode(specification.ConfigurationBeta.DefaultDirectoryMode,
+			specification.Configuration.DefaultDirectoryMode, synchronization.DefaultVersion.DefaultDirectoryMode()),
+	})
+	if err != nil {
+		m.logger.Debugf("Skipped bulk upload of %s: %v", mount.HostPath, err)
+		return
+	}
+	defer stream.Close()
+	defer setStreamDeadline(stream, syncBootstrapTimeout)()
+
+	start := time.Now()
+	if err := writeSyncArchive(stream, mount.HostPath, ignorer); err != nil {
+		m.logger.Warnf("Failed bulk upload of %s: %v", mount.HostPath, err)
+		return
+	}
+	var result guestExtractResult
+	if err := json.NewDecoder(stream).Decode(&result); err != nil {
+		m.logger.Warnf("Failed bulk upload of %s: unable to read result: %v", mount.HostPath, err)
+		return
+	}
+	if result.Error != "" {
+		m.logger.Warnf("Failed bulk upload of %s: %s", mount.HostPath, result.Error)
+		return
+	}
+	m.logger.Infof("Uploaded %d entries (%s) of %s to %s in %s", result.Entries,
+		humanize.Bytes(uint64(result.Bytes)), mount.HostPath, mount.RemotePath, time.Since(start).Round(time.Millisecond))
+}
+
+// betaDefaultMode returns the effective default mode of the remote endpoint
+// of a session, from its own configuration, the session configuration or the
+// mutagen default
+func betaDefaultMode(beta, session uint32, fallback filesystem.Mode) uint32 {
+	if beta != 0 {
+		return beta
+	}
+	if session != 0 {
+		return session
+	}
+	return uint32(fallback)
+}
+
+// syncIgnorer creates the ignorer of a session configuration the same way
+// mutagen endpoints do
+func syncIgnorer(configuration *synchronization.Configuration) (ignore.Ignorer, error) {
+	ignoreSyntax := configuration.IgnoreSyntax
+	if ignoreSyntax.IsDefault() {
+		ignoreSyntax = synchronization.DefaultVersion.DefaultIgnoreSyntax()
+	}
+
+	var ignores []string
+	ignores = append(ignores, configuration.DefaultIgnores...)
+	ignores = append(ignores, configuration.Ignores...)
+	var ignorer ignore.Ignorer
+	var err error
+	if ignoreSyntax == ignore.Syntax_SyntaxDocker {
+		ignorer, err = dockerignore.NewIgnorer(ignores)
+	} else {
+		ignorer, err = mutagenignore.NewIgnorer(ignores)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid ignores: %w", err)
+	}
+
+	ignoreVCSMode := configuration.IgnoreVCSMode
+	if ignoreVCSMode.IsDefault() {
+		ignoreVCSMode = synchronization.DefaultVersion.DefaultIgnoreVCSMode()
+	}
+	if ignoreVCSMode == ignore.IgnoreVCSMode_IgnoreVCSModeIgnore {
+		ignorer = ignore.IgnoreVCS(ignorer)
+	}
+	return ignorer, nil
+}
+
+// writeSyncArchive writes the directories and regular files of root that are
+// not ignored as a gzip compressed tar stream. Symbolic links are left to
+// mutagen, which validates them according to the symbolic link mode.
+func writeSyncArchive(w io.Writer, root string, ignorer ignore.Ignorer) error {
+	gz, err := gzip.NewWriterLevel(w, gzip.BestSpeed)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(gz)
+	err = walkSyncedTree(root, "", ignorer, false, func(name string, entry os.DirEntry) error {
+		if !entry.IsDir() {
+			return archiveFile(tw, filepath.Join(root, filepath.FromSlash(name)), name)
+		}
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeDir,
+			Name:     name + "/",
+			Mode:     0755,
+		}); err != nil {
+			return fmt.Errorf("unable to archive %s: %w", name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("unable to finish archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("unable to finish archive: %w", err)
+	}
+	return nil
+}
+
+// walkSyncedTree calls visit for the directories and regular files of a
+// directory, relative to root, that are not ignored. ignoreMask is set within
+// ignored directories traversed for the entries that may be unignored, as in
+// the scans of mutagen.
+func walkSyncedTree(root, relative string, ignorer ignore.Ignorer, ignoreMask bool, visit func(name string, entry os.DirEntry) error) error {
+	entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(relative)))
+	if err != nil {
+		return fmt.Errorf("unable to read directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := path.Join(relative, entry.Name())
+		directory := entry.IsDir()
+		if !directory && !entry.Type().IsRegular() {
+			continue
+		}
+
+		status, continueTraversal := ignorer.Ignore(name, directory)
+		mask := ignoreMask
+		switch status {
+		case ignore.IgnoreStatusNominal:
+			if ignoreMask && !continueTraversal {
+				continue
+			}
+		case ignore.IgnoreStatusIgnored:
+			if !continueTraversal {
+				continue
+			}
+			mask = true
+		case ignore.IgnoreStatusUnignored:
+			mask = false
+		}
+
+		if !mask {
+			if err := visit(name, entry); err != nil {
+				return err
+			}
+		}
+		if directory {
+			if err := walkSyncedTree(root, name, ignorer, mask, visit); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// archiveFile adds a regular file to the archive, with the size it has when
+// opened
+func archiveFile(tw *tar.Writer, hostPath, name string) error {
+	file, err := os.Open(hostPath)
+	if err != nil {
+		return fmt.Errorf("unable to open %s: %w", name, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("unable to stat %s: %w", name, err)
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     int64(info.Mode().Perm()),
+		Size:     info.Size(),
+		ModTime:  info.ModTime(),
+	}); err != nil {
+		return fmt.Errorf("unable to archive %s: %w", name, err)
+	}
+	if _, err := io.CopyN(tw, file, info.Size()); err != nil {
+		return fmt.Errorf("unable to archive %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package mutagen_bridge
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/dustin/go-humanize"
+	"github.com/mutagen-io/mutagen/pkg/synchronization"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core/ignore"
+)
+
+// Bind mounts are checked before their container is created, so that a
+// mistake such as "-v /:/host" or "-v ~:/home" does not start uploading the
+// whole disk or the credentials of the user to the remote host. Mounts of
+// sensitive host paths, or of directories holding more entries or data than
+// the configured limits, are refused unless their container is labeled with
+// "tinyscale.sync.<container-path>.allow=true".
+
+// sensitiveHomeDirs are the directories of the home directory holding
+// credentials, which are never synced unless explicitly allowed
+var sensitiveHomeDirs = []string{".ssh", ".aws"}
+
+// errSyncLimitReached stops the estimate of a synced directory
+var errSyncLimitReached = errors.New("sync limit reached")
+
+// CheckMounts returns an error explaining why a bind mount of a container
+// being created must not be synced, if any. Remote paths written by ad-hoc
+// sync sessions are always refused, host paths already synced for other
+// containers are not checked again.
+func (m *FileSyncManager) CheckMounts(mounts *ContainerMounts) error {
+	var adHocSyncs map[string]string
+	for _, mount := range mounts.Mounts {
+		if mount.Socket {
+			continue
+		}
+		if adHocSyncs == nil {
+			adHocSyncs = m.adHocSyncRemotePaths()
+		}
+		for name, remotePath := range adHocSyncs {
+			if remotePathsOverlap(remotePath, mount.RemotePath) {
+				return fmt.Errorf("refusing to sync %s to the remote host, the ad-hoc sync session %s already synchronizes %s; terminate it first with tsctl sync terminate %s",
+					mount.HostPath, name, remotePath, name)
+			}
+		}
+
+		if mount.Sync.Allow {
+			continue
+		}
+		m.mu.Lock()
+		_, shared := m.syncRootOf(mount.HostPath)
+		_, pushed := m.files[mount.HostPath]
+		m.mu.Unlock()
+		if shared != nil || pushed {
+			continue
+		}
+
+		override := fmt.Sprintf("set the label %s%s%s=true on the container to sync it anyway",
+			SyncLabelPrefix, path.Clean(mount.ContainerPath), syncAllowLabelSuffix)
+		if reason := sensitiveHostPath(mount.HostPath); reason != "" {
+			return fmt.Errorf("refusing to sync %s to the remote host, %s; mount a project directory instead, or %s",
+				mount.HostPath, reason, override)
+		}
+		if reason := m.exceedsSyncLimits(mount); reason != "" {
+			return fmt.Errorf("refusing to sync %s to the remote host, %s; ignore the generated files in .dockerignore or .tinyscale.yml, raise the limits with tsctl daemon start --sync-max-entries and --sync-max-size, or %s",
+				mount.HostPath, reason, override)
+		}
+	}
+	return nil
+}
+
+// sensitiveHostPath returns why a host path must not be synced, or an empty
+// string when it may be synced
+func sensitiveHostPath(hostPath string) string {
+	if filepath.Dir(hostPath) == hostPath {
+		return "it is the root directory"
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	home = filepath.Clean(home)
+	for _, dir := range sensitiveHomeDirs {
+		if hostPathContains(filepath.Join(home, dir), hostPath) {
+			return fmt.Sprintf("it holds credentials (~/%s)", dir)
+		}
+	}
+	if hostPathContains(hostPath, home) {
+		return "it contains your home directory"
+	}
+	return ""
+}
+
+// exceedsSyncLimits returns which limit the content of a host path exceeds,
+// or an empty string when it may be synced. The estimate honors the ignores
+// of the session that would sync it, and stops as soon as a limit is
+// exceeded. Host paths that cannot be read are left to the session, which
+// reports the errors.
+func (m *FileSyncManager) exceedsSyncLimits(mount *BindMount) string {
+	maxEntries, maxSize := m.transportConfig.SyncMaxEntries, m.transportConfig.SyncMaxSize
+	if maxEntries == 0 && maxSize == 0 {
+		return ""
+	}
+
+	var entries, size uint64
+	check := func() string {
+		if maxEntries != 0 && entries > maxEntries {
+			return fmt.Sprintf("it holds more than %d files and directories", maxEntries)
+		}
+		if maxSize != 0 && size > maxSize {
+			return fmt.Sprintf("it holds more than %s", humanize.Bytes(maxSize))
+		}
+		return ""
+	}
+
+	if mount.File {
+		if info, err := os.Stat(mount.HostPath); err == nil {
+			entries, size = 1, uint64(info.Size())
+		}
+		return check()
+	}
+
+	ignorer, err := m.mountIgnorer(mount)
+	if err != nil {
+		m.logger.Debugf("Skipped size check of %s: %v", mount.HostPath, err)
+		return ""
+	}
+	var reason string
+	err = walkSyncedTree(mount.HostPath, "", ignorer, false, func(_ string, entry os.DirEntry) error {
+		entries++
+		if !entry.IsDir() {
+			if info, err := entry.Info(); err == nil {
+				size += uint64(info.Size())
+			}
+		}
+		if reason = check(); reason != "" {
+			return errSyncLimitReached
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errSyncLimitReached) {
+		m.logger.Debugf("Skipped size check of %s: %v", mount.HostPath, err)
+	}
+	return reason
+}
+
+// mountIgnorer returns the ignorer of the session that would sync a mount,
+// from the global mutagen configuration, its project file, .dockerignore,
+// dependency directories and labels
+func (m *FileSyncManager) mountIgnorer(mount *BindMount) (ignore.Ignorer, error) {
+	configuration, err := baseSyncConfiguration(false, nil)
+	if err != nil {
+		return nil, err
+	}
+	project, _, err := loadProjectConfig(mount.HostPath)
+	if err == nil && project != nil {
+		configuration = synchronization.MergeConfigurations(configuration, project.Sync.ToInternal())
+	}
+	m.addMountIgnores(configuration, project, mount)
+	configuration.Ignores = append(configuration.Ignores, mount.Sync.Ignores...)
+	return syncIgnorer(configuration)
+}
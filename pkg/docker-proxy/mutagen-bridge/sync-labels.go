@@ -0,0 +1,87 @@
+package mutagen_bridge
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SyncLabelPrefix prefixes the container labels setting the sync of a
+	// bind mount by container path, e.g.
+	// "tinyscale.sync./app/dist.mode=remote-to-local",
+	// "tinyscale.sync./app.ignore=node_modules,*.log" and
+	// "tinyscale.sync./data.allow=true"
+	SyncLabelPrefix = "tinyscale.sync."
+
+	syncModeLabelSuffix   = ".mode"
+	syncIgnoreLabelSuffix = ".ignore"
+	syncAllowLabelSuffix  = ".allow"
+
+	// SyncModeOneWayReplica mirrors the local directory to the remote host,
+	// remote changes are overwritten
+	SyncModeOneWayReplica = "one-way-replica"
+	// SyncModeTwoWaySafe syncs both ways and leaves conflicts unresolved
+	SyncModeTwoWaySafe = "two-way-safe"
+	// SyncModeRemoteToLocal flows changes from the remote host back to the
+	// local directory, e.g. for build outputs. Local files are not deleted.
+	SyncModeRemoteToLocal = "remote-to-local"
+)
+
+// SyncOptions are the sync settings of a bind mount set by container labels
+type SyncOptions struct {
+	Mode    string   // One of the SyncMode constants, the daemon default when empty
+	Ignores []string // Ignore patterns added to the session
+	Allow   bool     // Whether the safety checks of the host path are skipped
+}
+
+// ParseSyncLabels parses the SyncLabelPrefix labels of a container into the
+// sync options by container path. Invalid labels are ignored and returned as
+// warnings.
+func ParseSyncLabels(labels map[string]string) (map[string]SyncOptions, []string) {
+	options := make(map[string]SyncOptions)
+	var warnings []string
+	for key, value := range labels {
+		rest, ok := strings.CutPrefix(key, SyncLabelPrefix)
+		if !ok {
+			continue
+		}
+
+		if target, ok := strings.CutSuffix(rest, syncModeLabelSuffix); ok && path.IsAbs(target) {
+			switch value {
+			case SyncModeOneWayReplica, SyncModeTwoWaySafe, SyncModeRemoteToLocal:
+				target = path.Clean(target)
+				option := options[target]
+				option.Mode = value
+				options[target] = option
+			default:
+				warnings = append(warnings, fmt.Sprintf("ignored label %s: unknown sync mode %q, expected %s, %s or %s",
+					key, value, SyncModeOneWayReplica, SyncModeTwoWaySafe, SyncModeRemoteToLocal))
+			}
+		} else if target, ok := strings.CutSuffix(rest, syncIgnoreLabelSuffix); ok && path.IsAbs(target) {
+			target = path.Clean(target)
+			option := options[target]
+			for _, pattern := range strings.Split(value, ",") {
+				if pattern = strings.TrimSpace(pattern); pattern != "" {
+					option.Ignores = append(option.Ignores, pattern)
+				}
+			}
+			options[target] = option
+		} else if target, ok := strings.CutSuffix(rest, syncAllowLabelSuffix); ok && path.IsAbs(target) {
+			allow, err := strconv.ParseBool(value)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("ignored label %s: expected true or false", key))
+				continue
+			}
+			target = path.Clean(target)
+			option := options[target]
+			option.Allow = allow
+			options[target] = option
+		} else {
+			warnings = append(warnings, fmt.Sprintf("ignored label %s: expected %s<container-path>%s, %s<container-path>%s or %s<container-path>%s",
+				key, SyncLabelPrefix, syncModeLabelSuffix, SyncLabelPrefix, syncIgnoreLabelSuffix, SyncLabelPrefix, syncAllowLabelSuffix))
+		}
+	}
+	return options, warnings
+}
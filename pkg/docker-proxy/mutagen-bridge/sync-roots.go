@@ -0,0 +1,218 @@
+package mutagen_bridge
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mutagen-io/mutagen/pkg/selection"
+	"github.com/mutagen-io/mutagen/pkg/synchronization/core/ignore"
+)
+
+// Nested bind mounts, such as "./:/app" and "./config:/etc/app", are synced
+// by the session of the outermost host path only. Since every host path maps
+// to the same path under SyncBasePath, the remote path of a nested mount is
+// already the matching subdirectory of the remote path of its root.
+//
+// A nested mount ignored by the session of its root, e.g. by .dockerignore,
+// has a session of its own instead, which would otherwise leave its remote
+// path empty. So does a nested mount with a sync mode other than the one of
+// its root, and nested single files are always pushed: the session of the
+// root ignores them when it is created after them or for the same container.
+// Sessions of descendants are otherwise replaced by the session of their new
+// root. A session already shared with other containers cannot change its
+// ignores, such nested mounts are then reported with a create warning.
+
+// hostPathContains returns whether hostPath is root or one of its descendants
+func hostPathContains(root, hostPath string) bool {
+	if root == hostPath {
+		return true
+	}
+	prefix := root
+	if !strings.HasSuffix(prefix, string(filepath.Separator)) {
+		prefix += string(filepath.Separator)
+	}
+	return strings.HasPrefix(hostPath, prefix)
+}
+
+// excludes returns whether the session of root ignores hostPath, one of its
+// descendants, or one of the parent directories of hostPath
+func (s *sharedSync) excludes(root, hostPath string) bool {
+	if s.Ignorer == nil || root == hostPath {
+		return false
+	}
+	rel, err := filepath.Rel(root, hostPath)
+	if err != nil {
+		return false
+	}
+	components := strings.Split(filepath.ToSlash(rel), "/")
+	for i := range components {
+		status, continueTraversal := s.Ignorer.Ignore(strings.Join(components[:i+1], "/"), true)
+		if status == ignore.IgnoreStatusIgnored && (!continueTraversal || i == len(components)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// setNestedPaths records, on the mounts of a container, the nested single
+// files and the nested mounts with a sync mode other than their own, to be
+// ignored by their session
+func setNestedPaths(mounts []*BindMount) {
+	for _, root := range mounts {
+		if root.Socket || root.File {
+			continue
+		}
+		for _, nested := range mounts {
+			if nested == root || nested.Socket || !hostPathContains(root.HostPath, nested.HostPath) {
+				continue
+			}
+			if nested.pushesFile() || (nested.Sync.Mode != "" && nested.Sync.Mode != root.Sync.Mode) {
+				root.addNestedPath(nested.HostPath)
+			}
+		}
+	}
+}
+
+// addNestedPath adds a descendant of the host path of a mount to the nested
+// paths ignored by its session
+func (mount *BindMount) addNestedPath(hostPath string) {
+	rel, err := filepath.Rel(mount.HostPath, hostPath)
+	if err != nil {
+		return
+	}
+	if rel = filepath.ToSlash(rel); !slices.Contains(mount.NestedPaths, rel) {
+		mount.NestedPaths = append(mount.NestedPaths, rel)
+	}
+}
+
+// ignoreNestedSyncs adds the descendants of the host path of a mount that
+// are already pushed, or synced by a session with another sync mode, to the
+// nested paths ignored by the session about to be created for it. They keep
+// being pushed or synced on their own. The caller must hold the lock.
+func (m *FileSyncManager) ignoreNestedSyncs(mount *BindMount) {
+	if mount.File {
+		return
+	}
+	for hostPath := range m.files {
+		if hostPath != mount.HostPath && hostPathContains(mount.HostPath, hostPath) {
+			mount.addNestedPath(hostPath)
+		}
+	}
+	for hostPath, nested := range m.syncs {
+		if hostPath != mount.HostPath && hostPathContains(mount.HostPath, hostPath) &&
+			nested.Options != nil && nested.Options.Mode != mount.Sync.Mode {
+			mount.addNestedPath(hostPath)
+		}
+	}
+}
+
+// syncRootOf returns the shared session syncing hostPath, the one of hostPath
+// itself or of its outermost synced ancestor not ignoring it. The caller must
+// hold the lock.
+func (m *FileSyncManager) syncRootOf(hostPath string) (string, *sharedSync) {
+	var root string
+	var shared *sharedSync
+	for candidate, candidateSync := range m.syncs {
+		if hostPathContains(candidate, hostPath) && !candidateSync.excludes(candidate, hostPath) &&
+			(shared == nil || len(candidate) < len(root)) {
+			root, shared = candidate, candidateSync
+		}
+	}
+	return root, shared
+}
+
+// rootsFirst returns the mounts sorted so that the ancestors of host paths
+// come before their descendants
+func rootsFirst(mounts []*BindMount) []*BindMount {
+	sorted := make([]*BindMount, len(mounts))
+	copy(sorted, mounts)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].HostPath) < len(sorted[j].HostPath)
+	})
+	return sorted
+}
+
+// absorbNestedSyncs replaces the sessions of the descendants of root by the
+// new session of root, the containers using them now use the session of root.
+// The sessions of the descendants it ignores, such as those created with
+// another sync mode, are kept, so are the pushed files, which the session
+// should ignore. The caller must hold the lock.
+func (m *FileSyncManager) absorbNestedSyncs(root string, shared *sharedSync) {
+	for hostPath := range m.files {
+		if hostPathContains(root, hostPath) && !shared.excludes(root, hostPath) {
+			m.logger.Warnf("The sync session %s of %s also writes the pushed file %s, the containers binding it may miss its changes",
+				shared.SessionID, root, hostPath)
+		}
+	}
+
+	var identifiers []string
+	for hostPath, nested := range m.syncs {
+		if hostPath == root || !hostPathContains(root, hostPath) || shared.excludes(root, hostPath) {
+			continue
+		}
+		for containerID := range nested.Containers {
+			shared.Containers[containerID] = true
+		}
+		identifiers = append(identifiers, nested.SessionID)
+		delete(m.syncs, hostPath)
+		m.logger.Infof("Replacing sync session %s of %s by the session %s of its parent directory %s",
+			nested.SessionID, hostPath, shared.SessionID, root)
+	}
+	if len(identifiers) == 0 {
+		return
+	}
+
+	for _, containerMounts := range m.containerMounts {
+		for _, mount := range containerMounts.Mounts {
+			if !mount.Socket && !mount.pushesFile() && hostPathContains(root, mount.HostPath) && !shared.excludes(root, mount.HostPath) {
+				mount.SessionID = shared.SessionID
+			}
+		}
+	}
+
+	selected := &selection.Selection{Specifications: identifiers}
+	if err := m.mutagenSyncMgr.Terminate(context.Background(), selected, ""); err != nil {
+		m.logger.Infof("Error terminating nested sync sessions: %s", err)
+	}
+}
+
+// SharedSyncWarnings returns warnings for the mounts of a container being
+// created that a shared session syncs against their settings: mounts whose
+// sync mode label is ignored, because their host path is already synced by a
+// shared session created with another sync mode, and single files pushed
+// within a directory such a session syncs.
+func (m *FileSyncManager) SharedSyncWarnings(mounts *ContainerMounts) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var warnings []string
+	for _, mount := range mounts.Mounts {
+		if mount.Socket {
+			continue
+		}
+		if mount.pushesFile() {
+			if _, pushed := m.files[mount.HostPath]; pushed {
+				continue
+			}
+			if root, shared := m.syncRootOf(mount.HostPath); shared != nil {
+				warnings = append(warnings, fmt.Sprintf("%s of %s is also synced by the session %s of %s, shared with other containers; the container may miss its changes until they stop",
+					mount.HostPath, mount.ContainerPath, shared.SessionID, root))
+			}
+			continue
+		}
+		if mount.Sync.Mode == "" {
+			continue
+		}
+		root, shared := m.syncRootOf(mount.HostPath)
+		if shared == nil || (shared.Options != nil && shared.Options.Mode == mount.Sync.Mode) {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("ignored sync mode %s of %s: %s is already synced by the session %s of %s, shared with other containers; stop them to change its sync mode",
+			mount.Sync.Mode, mount.ContainerPath, mount.HostPath, shared.SessionID, root))
+	}
+	return warnings
+}
@@ -0,0 +1,285 @@
+package mutagen_bridge
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/teamycloud/tsctl/pkg/utils"
+)
+
+const (
+	// udpForwardPath is the guest endpoint relaying datagrams to a remote UDP port
+	udpForwardPath = "/tinyscale/v1/host-exec/forward-udp"
+	// udpSessionIdleTimeout is how long the stream of a local client is kept without traffic
+	udpSessionIdleTimeout = 2 * time.Minute
+	// udpSessionQueueSize is the number of datagrams of a local client queued
+	// while its stream opens or is busy, further datagrams are dropped
+	udpSessionQueueSize = 64
+	// udpDialRetryDelay is how long the datagrams of a local client are
+	// dropped after its guest stream failed to open
+	udpDialRetryDelay = 5 * time.Second
+)
+
+// GuestDialer opens a stream to an endpoint of the guest agent on the remote
+// host. The request is sent with `Upgrade: tcp` and the JSON encoded payload,
+// and the returned connection carries the upgraded stream.
+type GuestDialer func(path string, payload any) (net.Conn, error)
+
+// SetGuestDialer sets the dialer used to reach the guest agent, which relays
+// UDP port forwards
+func (m *PortForwardManager) SetGuestDialer(dialer GuestDialer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.guestDialer = dialer
+}
+
+// setupUDPForward listens on the local UDP port of a binding and relays the
+// datagrams to the remote host port through the guest agent. The caller must
+// hold the lock.
+func (m *PortForwardManager) setupUDPForward(binding *PortBinding) error {
+	if m.guestDialer == nil {
+		return fmt.Errorf("no guest dialer configured")
+	}
+	hostPort, err := strconv.Atoi(binding.HostPort)
+	if err != nil {
+		return fmt.Errorf("invalid host port %s: %w", binding.HostPort, err)
+	}
+
+	conn, err := net.ListenPacket("udp", net.JoinHostPort(binding.HostIP, binding.LocalPort))
+	if err != nil {
+		return fmt.Errorf("unable to listen on UDP port %s: %w", binding.LocalPort, err)
+	}
+	binding.PacketConn = conn
+
+	forwarder := &udpForwarder{
+		conn:     conn,
+		hostIP:   binding.RemoteHost,
+		hostPort: hostPort,
+		dial:     m.guestDialer,
+		logger:   m.logger,
+		sessions: make(map[string]*udpSession),
+	}
+	go forwarder.serve()
+
+	m.logger.Infof("Created UDP port forward on port %s", binding.LocalPort)
+	return nil
+}
+
+// udpForwarder relays the datagrams received on a local UDP socket to a port
+// on the remote host, using one guest stream per local client address so
+// that replies find their way back
+type udpForwarder struct {
+	conn     net.PacketConn
+	hostIP   string // Remote loopback address of the port, the guest default when empty
+	hostPort int
+	dial     GuestDialer
+	logger   *logging.Logger
+
+	mu       sync.Mutex
+	sessions map[string]*udpSession
+}
+
+// udpSession is the guest stream of a local client address. The stream is
+// opened and written by a goroutine of its own, so that a slow guest does not
+// hold up the datagrams of the other clients.
+type udpSession struct {
+	outgoing   chan []byte   // Datagrams of the local client waiting for the stream
+	closed     chan struct{} // Closed with the session
+	closeOnce  sync.Once
+	lastActive atomic.Int64 // Unix nanoseconds of the last datagram in either direction
+	retryAt    time.Time    // Set once the stream failed to open, guarded by the lock of the forwarder
+
+	mu     sync.Mutex
+	stream net.Conn // Nil until opened
+}
+
+// close closes the session and its stream, if opened
+func (s *udpSession) close() {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.stream != nil {
+			_ = s.stream.Close()
+		}
+	})
+}
+
+// guestUDPForwardRequest is the payload of udpForwardPath
+type guestUDPForwardRequest struct {
+	Host string `json:"host,omitempty"`
+	Port int    `json:"port"`
+}
+
+// serve reads datagrams from the local socket until it is closed
+func (f *udpForwarder) serve() {
+	done := make(chan struct{})
+	go f.expireSessions(done)
+	defer func() {
+		close(done)
+		f.closeSessions()
+	}()
+
+	buf := make([]byte, utils.MaxDatagramSize)
+	for {
+		n, addr, err := f.conn.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				f.logger.Warnf("UDP port forward to %d stopped: %v", f.hostPort, err)
+			}
+			return
+		}
+
+		session := f.session(addr)
+		if session == nil {
+			continue
+		}
+		session.lastActive.Store(time.Now().UnixNano())
+		datagram := make([]byte, n)
+		copy(datagram, buf[:n])
+		select {
+		case session.outgoing <- datagram:
+		default:
+			f.logger.Debugf("Dropped datagram from %s: too many datagrams waiting for the guest", addr)
+		}
+	}
+}
+
+// session returns the session of a local client address, opening its stream
+// in the background on the first datagram. It returns nil while the datagrams
+// of the client are dropped, after its stream failed to open.
+func (f *udpForwarder) session(addr net.Addr) *udpSession {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if session, ok := f.sessions[addr.String()]; ok {
+		if session.retryAt.IsZero() {
+			return session
+		}
+		if time.Now().Before(session.retryAt) {
+			return nil
+		}
+	}
+
+	session := &udpSession{
+		outgoing: make(chan []byte, udpSessionQueueSize),
+		closed:   make(chan struct{}),
+	}
+	session.lastActive.Store(time.Now().UnixNano())
+	f.sessions[addr.String()] = session
+
+	go f.relayDatagrams(addr, session)
+	return session
+}
+
+// relayDatagrams opens the guest stream of a local client and writes the
+// datagrams of the client to it until the session is closed. A session whose
+// stream failed to open is kept for udpDialRetryDelay, so that the guest is
+// not dialed again for every datagram of the client.
+func (f *udpForwarder) relayDatagrams(addr net.Addr, session *udpSession) {
+	stream, err := f.dial(udpForwardPath, &guestUDPForwardRequest{Host: f.hostIP, Port: f.hostPort})
+	if err != nil {
+		f.logger.Debugf("Dropping datagrams from %s for %s: unable to open guest stream: %v", addr, udpDialRetryDelay, err)
+		f.mu.Lock()
+		session.retryAt = time.Now().Add(udpDialRetryDelay)
+		f.mu.Unlock()
+		session.close()
+		return
+	}
+	defer f.closeSession(addr.String(), session)
+
+	session.mu.Lock()
+	select {
+	case <-session.closed:
+		session.mu.Unlock()
+		_ = stream.Close()
+		return
+	default:
+	}
+	session.stream = stream
+	session.mu.Unlock()
+
+	go f.relayReplies(addr, session, stream)
+
+	for {
+		select {
+		case <-session.closed:
+			return
+		case datagram := <-session.outgoing:
+			if err := utils.WriteDatagram(stream, datagram); err != nil {
+				f.logger.Debugf("Failed to relay datagram from %s: %v", addr, err)
+				return
+			}
+		}
+	}
+}
+
+// relayReplies writes the datagrams coming back from the guest to the local
+// client until the stream is closed
+func (f *udpForwarder) relayReplies(addr net.Addr, session *udpSession, stream net.Conn) {
+	defer f.closeSession(addr.String(), session)
+
+	buf := make([]byte, utils.MaxDatagramSize)
+	for {
+		n, err := utils.ReadDatagram(stream, buf)
+		if err != nil {
+			return
+		}
+		session.lastActive.Store(time.Now().UnixNano())
+		if _, err := f.conn.WriteTo(buf[:n], addr); err != nil {
+			return
+		}
+	}
+}
+
+// expireSessions closes the streams that stayed idle for
+// udpSessionIdleTimeout, until done is closed
+func (f *udpForwarder) expireSessions(done <-chan struct{}) {
+	ticker := time.NewTicker(udpSessionIdleTimeout / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		f.mu.Lock()
+		for key, session := range f.sessions {
+			if time.Since(time.Unix(0, session.lastActive.Load())) >= udpSessionIdleTimeout {
+				session.close()
+				delete(f.sessions, key)
+			}
+		}
+		f.mu.Unlock()
+	}
+}
+
+// closeSession closes the stream of a local client address
+func (f *udpForwarder) closeSession(key string, session *udpSession) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.sessions[key] == session {
+		delete(f.sessions, key)
+	}
+	session.close()
+}
+
+// closeSessions closes the streams of all local clients
+func (f *udpForwarder) closeSessions() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for key, session := range f.sessions {
+		session.close()
+		delete(f.sessions, key)
+	}
+}
@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
+	"path"
+	"strconv"
 
 	mutagen_bridge "github.com/teamycloud/tsctl/pkg/docker-proxy/mutagen-bridge"
 )
@@ -17,8 +20,9 @@ type ContainerCreateResponse struct {
 	Message  string   `json:"Message,omitempty"`
 }
 
-// handleContainerCreate extracts port bindings from container create request and stores them
-func (p *DockerAPIProxy) handleContainerCreateRequest(req *http.Request) {
+// handleContainerCreate extracts port bindings from container create request and stores them.
+// It returns a Docker API error when the bind mounts must not be synced.
+func (p *DockerAPIProxy) handleContainerCreateRequest(req *http.Request) (refusal *http.Response) {
 	var originalReqBody []byte
 	var replacedReqBody []byte
 
@@ -44,6 +48,7 @@ func (p *DockerAPIProxy) handleContainerCreateRequest(req *http.Request) {
 
 	// Parse request JSON to get port bindings and bind mounts
 	var createReq struct {
+		Labels     map[string]string `json:"Labels"`
 		HostConfig struct {
 			PortBindings map[string][]struct {
 				HostIp   string `json:"HostIp"`
@@ -56,6 +61,7 @@ func (p *DockerAPIProxy) handleContainerCreateRequest(req *http.Request) {
 				Target   string `json:"Target"`
 				ReadOnly bool   `json:"ReadOnly,omitempty"`
 			} `json:"Mounts"`
+			ExtraHosts []string `json:"ExtraHosts"`
 		} `json:"HostConfig"`
 	}
 
@@ -64,37 +70,42 @@ func (p *DockerAPIProxy) handleContainerCreateRequest(req *http.Request) {
 		return
 	}
 
-	// Extract port bindings
-	portBindings := make(map[string][]string)
+	// Extract port bindings, bindings without a host port are assigned by
+	// Docker and picked up when the container starts
+	portBindings := make(map[string][]mutagen_bridge.HostBinding)
+	hasHostIP := false
 	for containerPort, bindings := range createReq.HostConfig.PortBindings {
-		hostPorts := make([]string, 0)
 		for _, binding := range bindings {
-			if binding.HostPort != "" {
-				hostPorts = append(hostPorts, binding.HostPort)
+			portBindings[containerPort] = append(portBindings[containerPort], mutagen_bridge.HostBinding{
+				HostIP:   binding.HostIp,
+				HostPort: binding.HostPort,
+			})
+			if binding.HostIp != "" {
+				hasHostIP = true
 			}
 		}
-		if len(hostPorts) > 0 {
-			portBindings[containerPort] = hostPorts
-			log.Printf("Port binding found: %s -> %v", containerPort, hostPorts)
-		}
+		log.Printf("Port binding found: %s -> %v", containerPort, portBindings[containerPort])
 	}
 
+	var localPortLabels map[string]string
 	if len(portBindings) > 0 {
-		p.portForwardMgr.StorePortBindingsStart(req, portBindings)
+		p.addCreateWarnings(req, p.portForwardMgr.StorePortBindingsStart(req, portBindings))
+		localPortLabels = p.portForwardMgr.LocalPortLabels(req)
 	}
 
 	mounts := make([]string, 0)
+	var parsedMounts *mutagen_bridge.ContainerMounts
 	if len(createReq.HostConfig.Binds) > 0 {
 		mounts = append(mounts, createReq.HostConfig.Binds...)
 	}
 	if len(createReq.HostConfig.Mounts) > 0 {
 		for _, mount := range createReq.HostConfig.Mounts {
-			if mount.Type == "bind" {
-				m := fmt.Sprintf("%s:%s", mount.Source, mount.Target)
+			if mount.Type == mutagen_bridge.MountTypeBind {
+				spec := &mutagen_bridge.MountSpec{Type: mount.Type, Source: mount.Source, Target: mount.Target}
 				if mount.ReadOnly {
-					m = fmt.Sprintf("%s:ro", m)
+					spec.Options = []string{"ro"}
 				}
-				mounts = append(mounts, m)
+				mounts = append(mounts, spec.String())
 			}
 		}
 	}
@@ -102,78 +113,330 @@ func (p *DockerAPIProxy) handleContainerCreateRequest(req *http.Request) {
 	if len(mounts) > 0 {
 		log.Printf("Bind mounts found: %d mounts", len(mounts))
 
-		p.fileSyncMgr.StoreBindMountsStart(req, mounts)
-		parsedMounts := p.fileSyncMgr.GetMounts(req)
+		syncOptions, warnings := mutagen_bridge.ParseSyncLabels(createReq.Labels)
+		p.addCreateWarnings(req, warnings)
+		p.fileSyncMgr.StoreBindMountsStart(req, mounts, syncOptions)
+		parsedMounts = p.fileSyncMgr.GetMounts(req)
 		if parsedMounts != nil && len(parsedMounts.Mounts) > 0 {
+			// Refuse sensitive or huge host paths before anything is synced
+			if err := p.fileSyncMgr.CheckMounts(parsedMounts); err != nil {
+				log.Printf("Refused container create: %v", err)
+				return dockerErrorResponse(req, http.StatusBadRequest, err.Error())
+			}
+
+			// Invalid project files are reported to docker run
+			p.addCreateWarnings(req, p.fileSyncMgr.ProjectConfigWarnings(parsedMounts))
+			// So are the mounts that shared sessions cannot sync as asked
+			p.addCreateWarnings(req, p.fileSyncMgr.SharedSyncWarnings(parsedMounts))
+
 			// Create mount directories on remote host before starting
 			if err := p.createRemoteMountDirectories(parsedMounts); err != nil {
 				log.Printf("Failed to create remote mount directories: %v", err)
 			}
+
+			// Single files must exist before Docker mounts them, Docker
+			// would create a directory instead
+			p.fileSyncMgr.PushFiles(parsedMounts)
+
+			// Sockets such as $SSH_AUTH_SOCK are forwarded back to the
+			// local socket instead of synced
+			if sockets := parsedMounts.SocketPaths(); len(sockets) > 0 {
+				p.portForwardMgr.StoreSocketForwardsStart(req, sockets, p.promptIdentifier)
+			}
 		}
+	}
+
+	// Forward remote ports back to the local machine for containers
+	// reaching it as host.docker.internal
+	reverseLabels, addGatewayHost := p.portForwardMgr.ReverseForwardRequest(createReq.Labels, createReq.HostConfig.ExtraHosts)
 
-		// Rewrite mount paths in the request body using generic map manipulation
-		// to preserve all unknown fields
-		var createReqMap map[string]interface{}
-		if err := json.Unmarshal(originalReqBody, &createReqMap); err != nil {
-			log.Printf("Failed to parse request for mount rewriting: %v", err)
+	if len(mounts) == 0 && !hasHostIP && len(localPortLabels) == 0 && len(reverseLabels) == 0 && !addGatewayHost {
+		return
+	}
+
+	// Rewrite the request body using generic map manipulation to preserve
+	// all unknown fields
+	var createReqMap map[string]interface{}
+	if err := json.Unmarshal(originalReqBody, &createReqMap); err != nil {
+		log.Printf("Failed to parse request for rewriting: %v", err)
+		return
+	}
+
+	needsRewrite := false
+	if hostConfig, ok := createReqMap["HostConfig"].(map[string]interface{}); ok {
+		if len(localPortLabels) > 0 {
+			needsRewrite = assignRemotePorts(createReqMap, hostConfig, localPortLabels) || needsRewrite
+		}
+
+		if hasHostIP {
+			needsRewrite = rewritePortBindingHostIPs(hostConfig) || needsRewrite
+		}
+
+		if len(mounts) > 0 {
+			needsRewrite = rewriteMountSources(hostConfig) || needsRewrite
+		}
+
+		if parsedMounts != nil {
+			if volumes := parsedMounts.DependencyVolumes(); len(volumes) > 0 {
+				needsRewrite = addDependencyVolumes(createReqMap, hostConfig, volumes) || needsRewrite
+			}
+		}
+
+		if len(reverseLabels) > 0 || addGatewayHost {
+			needsRewrite = addReverseForwardHost(createReqMap, hostConfig, reverseLabels, addGatewayHost) || needsRewrite
+		}
+	}
+
+	// Marshal back if we made changes
+	if needsRewrite {
+		modifiedBody, err := json.Marshal(createReqMap)
+		if err != nil {
+			log.Printf("Failed to marshal modified request: %v", err)
 		} else {
-			needsRewrite := false
-
-			if hostConfig, ok := createReqMap["HostConfig"].(map[string]interface{}); ok {
-				// Rewrite HostConfig.Binds (string array format)
-				if binds, ok := hostConfig["Binds"].([]interface{}); ok && len(binds) > 0 {
-					newBinds := make([]interface{}, 0, len(binds))
-					for _, bindIface := range binds {
-						if bind, ok := bindIface.(string); ok {
-							newBind := rewriteBindMount(bind, mutagen_bridge.SyncBasePath)
-							newBinds = append(newBinds, newBind)
-							if newBind != bind {
-								needsRewrite = true
-								log.Printf("Rewriting bind mount: %s -> %s", bind, newBind)
-							}
-						}
-					}
-					hostConfig["Binds"] = newBinds
+			replacedReqBody = modifiedBody
+			log.Printf("Request body rewritten with sync base path and port binding modifications")
+		}
+	}
+	return nil
+}
+
+// rewriteMountSources replaces the host paths of HostConfig.Binds and
+// HostConfig.Mounts with their remote sync paths
+func rewriteMountSources(hostConfig map[string]interface{}) bool {
+	needsRewrite := false
+
+	// Rewrite HostConfig.Binds (string array format)
+	if binds, ok := hostConfig["Binds"].([]interface{}); ok && len(binds) > 0 {
+		newBinds := make([]interface{}, 0, len(binds))
+		for _, bindIface := range binds {
+			if bind, ok := bindIface.(string); ok {
+				newBind := rewriteBindMount(bind)
+				newBinds = append(newBinds, newBind)
+				if newBind != bind {
+					needsRewrite = true
+					log.Printf("Rewriting bind mount: %s -> %s", bind, newBind)
 				}
+			}
+		}
+		hostConfig["Binds"] = newBinds
+	}
+
+	// Rewrite HostConfig.Mounts (object array format)
+	if mountsArray, ok := hostConfig["Mounts"].([]interface{}); ok && len(mountsArray) > 0 {
+		for _, mountIface := range mountsArray {
+			if mount, ok := mountIface.(map[string]interface{}); ok {
+				mountType, _ := mount["Type"].(string)
+				if mountType == mutagen_bridge.MountTypeBind {
+					source, _ := mount["Source"].(string)
+					target, _ := mount["Target"].(string)
 
-				// Rewrite HostConfig.Mounts (object array format)
-				if mountsArray, ok := hostConfig["Mounts"].([]interface{}); ok && len(mountsArray) > 0 {
-					for _, mountIface := range mountsArray {
-						if mount, ok := mountIface.(map[string]interface{}); ok {
-							mountType, _ := mount["Type"].(string)
-							if mountType == "bind" {
-								source, _ := mount["Source"].(string)
-								target, _ := mount["Target"].(string)
-
-								if source != "" && target != "" {
-									newSource := fmt.Sprintf("%s%s", mutagen_bridge.SyncBasePath, source)
-									if newSource != source {
-										mount["Source"] = newSource
-										needsRewrite = true
-										log.Printf("Rewriting mount source: %s -> %s", source, newSource)
-									}
-								}
-							}
+					if source != "" && target != "" {
+						newSource := mutagen_bridge.RemotePath(source)
+						if newSource != source {
+							mount["Source"] = newSource
+							needsRewrite = true
+							log.Printf("Rewriting mount source: %s -> %s", source, newSource)
 						}
 					}
 				}
 			}
+		}
+	}
+
+	return needsRewrite
+}
+
+// addDependencyVolumes mounts remote named volumes over the dependency
+// directories of bind mounts. Container paths the request already mounts
+// something on, such as an anonymous volume over node_modules, are skipped.
+func addDependencyVolumes(createReqMap map[string]interface{}, hostConfig map[string]interface{}, volumes []mutagen_bridge.DependencyVolume) bool {
+	targets := make(map[string]bool)
+	binds, _ := hostConfig["Binds"].([]interface{})
+	for _, bindIface := range binds {
+		if bind, ok := bindIface.(string); ok {
+			if spec, err := mutagen_bridge.ParseBindSpec(bind); err == nil {
+				targets[path.Clean(spec.Target)] = true
+			}
+		}
+	}
+	if mountsArray, ok := hostConfig["Mounts"].([]interface{}); ok {
+		for _, mountIface := range mountsArray {
+			if mount, ok := mountIface.(map[string]interface{}); ok {
+				if target, _ := mount["Target"].(string); target != "" {
+					targets[path.Clean(target)] = true
+				}
+			}
+		}
+	}
+	if anonymous, ok := createReqMap["Volumes"].(map[string]interface{}); ok {
+		for target := range anonymous {
+			targets[path.Clean(target)] = true
+		}
+	}
+
+	added := false
+	for _, volume := range volumes {
+		if targets[volume.Target] {
+			continue
+		}
+		targets[volume.Target] = true
+		binds = append(binds, volume.Name+":"+volume.Target)
+		added = true
+		log.Printf("Mounting remote volume %s over dependency directory %s", volume.Name, volume.Target)
+	}
+	if added {
+		hostConfig["Binds"] = binds
+	}
+	return added
+}
+
+// addReverseForwardHost records the reverse forwards of a container in its
+// labels and adds the host.docker.internal:host-gateway extra host containers
+// reach them with
+func addReverseForwardHost(createReqMap map[string]interface{}, hostConfig map[string]interface{}, reverseLabels map[string]string, addGatewayHost bool) bool {
+	if len(reverseLabels) > 0 {
+		labels, _ := createReqMap["Labels"].(map[string]interface{})
+		if labels == nil {
+			labels = make(map[string]interface{})
+		}
+		for label, value := range reverseLabels {
+			labels[label] = value
+		}
+		createReqMap["Labels"] = labels
+		log.Printf("Adding reverse port forwards: %v", reverseLabels)
+	}
+
+	if addGatewayHost {
+		extraHosts, _ := hostConfig["ExtraHosts"].([]interface{})
+		hostConfig["ExtraHosts"] = append(extraHosts, "host.docker.internal:host-gateway")
+		log.Printf("Adding extra host host.docker.internal:host-gateway for reverse port forwards")
+	}
+	return true
+}
+
+// assignRemotePorts clears the host port of bindings without a HostIp, so that
+// Docker picks a free port on the remote host, and records the local ports in
+// the container labels. This is used for containers with their own loopback
+// address, which keep the requested port locally.
+func assignRemotePorts(createReqMap map[string]interface{}, hostConfig map[string]interface{}, localPortLabels map[string]string) bool {
+	portBindings, ok := hostConfig["PortBindings"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	needsRewrite := false
+	for _, bindingsIface := range portBindings {
+		bindings, _ := bindingsIface.([]interface{})
+		for _, bindingIface := range bindings {
+			binding, ok := bindingIface.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			hostIP, _ := binding["HostIp"].(string)
+			hostPort, _ := binding["HostPort"].(string)
+			if hostIP == "" && hostPort != "" {
+				binding["HostPort"] = ""
+				needsRewrite = true
+			}
+		}
+	}
+	if !needsRewrite {
+		return false
+	}
+
+	labels, _ := createReqMap["Labels"].(map[string]interface{})
+	if labels == nil {
+		labels = make(map[string]interface{})
+	}
+	for label, localPort := range localPortLabels {
+		labels[label] = localPort
+	}
+	createReqMap["Labels"] = labels
+
+	log.Printf("Rewriting port bindings to let the remote host assign ports, local ports: %v", localPortLabels)
+	return true
+}
+
+// rewritePortBindingHostIPs publishes the port bindings with a HostIp on the
+// remote loopback address of the same family. The address is where the user
+// wants the port on the local machine, the remote host may not have it, and
+// the remote port only needs to be reachable by the forward. Bindings that
+// become identical, e.g. 127.0.0.1:8080 and 127.0.0.2:8080, are published
+// once, bindings of ports assigned by Docker are all kept.
+func rewritePortBindingHostIPs(hostConfig map[string]interface{}) bool {
+	portBindings, ok := hostConfig["PortBindings"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	needsRewrite := false
+	for containerPort, bindingsIface := range portBindings {
+		bindings, ok := bindingsIface.([]interface{})
+		if !ok {
+			continue
+		}
+
+		seen := make(map[string]bool)
+		newBindings := make([]interface{}, 0, len(bindings))
+		for _, bindingIface := range bindings {
+			binding, ok := bindingIface.(map[string]interface{})
+			if !ok {
+				newBindings = append(newBindings, bindingIface)
+				continue
+			}
+
+			hostIP, _ := binding["HostIp"].(string)
+			if hostIP != "" {
+				if remoteIP := mutagen_bridge.RemoteLoopback(hostIP); remoteIP != hostIP {
+					binding["HostIp"] = remoteIP
+					hostIP = remoteIP
+					needsRewrite = true
+				}
+			}
 
-			// Marshal back if we made changes
-			if needsRewrite {
-				modifiedBody, err := json.Marshal(createReqMap)
-				if err != nil {
-					log.Printf("Failed to marshal modified request: %v", err)
-				} else {
-					replacedReqBody = modifiedBody
-					log.Printf("Request body rewritten with sync base path modifications")
+			hostPort, _ := binding["HostPort"].(string)
+			if hostPort != "" {
+				key := net.JoinHostPort(hostIP, hostPort)
+				if seen[key] {
+					needsRewrite = true
+					continue
 				}
+				seen[key] = true
 			}
+			newBindings = append(newBindings, binding)
 		}
+		portBindings[containerPort] = newBindings
+	}
+
+	if needsRewrite {
+		log.Printf("Rewriting port bindings to publish on the remote loopback address")
+	}
+	return needsRewrite
+}
+
+// dockerErrorResponse builds the response of a request refused by the proxy,
+// in the format of the errors of the Docker API
+func dockerErrorResponse(req *http.Request, statusCode int, message string) *http.Response {
+	body, _ := json.Marshal(map[string]string{"message": message})
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
 	}
 }
 
 func (p *DockerAPIProxy) handleContainerCreateResponse(req *http.Request, resp *http.Response) {
+	var warnings []string
+	if cached, ok := p.createWarnings.LoadAndDelete(req); ok {
+		warnings, _ = cached.([]string)
+	}
+
 	if resp.StatusCode != 201 {
 		log.Printf("Container create detected with unexpected status code: %d", resp.StatusCode)
 		p.portForwardMgr.StorePortBindingsEnd(req, "")
@@ -201,6 +464,60 @@ func (p *DockerAPIProxy) handleContainerCreateResponse(req *http.Request, resp *
 	log.Printf("Container created with ID: %s", createResp.Id)
 	p.portForwardMgr.StorePortBindingsEnd(req, createResp.Id)
 	p.fileSyncMgr.StoreBindMountsEnd(req, createResp.Id)
+
+	if len(warnings) > 0 {
+		if err := appendCreateWarnings(resp, warnings); err != nil {
+			log.Printf("Failed to add warnings to container create response: %v", err)
+		}
+	}
+}
+
+// addCreateWarnings records warnings to add to the response of a container
+// create request
+func (p *DockerAPIProxy) addCreateWarnings(req *http.Request, warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	if cached, ok := p.createWarnings.Load(req); ok {
+		existing, _ := cached.([]string)
+		warnings = append(existing, warnings...)
+	}
+	p.createWarnings.Store(req, warnings)
+}
+
+// appendCreateWarnings adds warnings to the Warnings of a container create
+// response, which the Docker CLI prints after creating the container
+func appendCreateWarnings(resp *http.Response, warnings []string) error {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		resp.Body = io.NopCloser(bytes.NewReader([]byte(fmt.Sprintf("Internal agent error: %v", err))))
+		return err
+	}
+
+	// Rewrite through a map to preserve fields unknown to ContainerCreateResponse
+	var respMap map[string]interface{}
+	if err := json.Unmarshal(body, &respMap); err != nil {
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return err
+	}
+	existing, _ := respMap["Warnings"].([]interface{})
+	for _, warning := range warnings {
+		existing = append(existing, warning)
+	}
+	respMap["Warnings"] = existing
+
+	rewritten, err := json.Marshal(respMap)
+	if err != nil {
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(rewritten))
+	resp.ContentLength = int64(len(rewritten))
+	resp.TransferEncoding = nil
+	resp.Header.Set("Content-Length", strconv.Itoa(len(rewritten)))
+	return nil
 }
 
 // handleContainerCreate extracts port bindings from container create request and stores them
@@ -0,0 +1,202 @@
+package docker_proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	mutagen_bridge "github.com/teamycloud/tsctl/pkg/docker-proxy/mutagen-bridge"
+)
+
+// handleContainerInspectResponse reverses the SyncBasePath rewrite done on
+// container create in the responses of `GET /containers/{id}/json` and
+// `GET /containers/json`, so clients see their local paths, and reports the
+// local ports of forwards that could not use the remote port number, so that
+// `docker port` shows where the container is reachable
+func (p *DockerAPIProxy) handleContainerInspectResponse(resp *http.Response, list bool) {
+	if resp.StatusCode != http.StatusOK || resp.Body == nil {
+		return
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		p.logger.Warnf("Failed to read container inspect response: %v", err)
+		resp.Body = io.NopCloser(bytes.NewReader([]byte(fmt.Sprintf("Internal agent error: %v", err))))
+		return
+	}
+
+	rewritten, err := rewriteContainersInBody(body, list, func(container map[string]interface{}) bool {
+		changed := reverseSyncPaths(container)
+		return p.restoreLocalPorts(container) || changed
+	})
+	if err != nil {
+		p.logger.Debugf("Failed to rewrite container inspect response: %v", err)
+		rewritten = nil
+	}
+	if rewritten == nil {
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(rewritten))
+	resp.ContentLength = int64(len(rewritten))
+	resp.TransferEncoding = nil
+	resp.Header.Set("Content-Length", strconv.Itoa(len(rewritten)))
+}
+
+// rewriteContainersInBody rewrites an inspect object, or a list of them, and
+// returns nil if nothing had to be changed
+func rewriteContainersInBody(body []byte, list bool, rewrite func(container map[string]interface{}) bool) ([]byte, error) {
+	// Keep numbers as they are, int64 fields such as Memory would otherwise
+	// be marshaled back as floats
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	changed := false
+	var document interface{}
+	if list {
+		var containers []interface{}
+		if err := decoder.Decode(&containers); err != nil {
+			return nil, err
+		}
+		for _, container := range containers {
+			if containerMap, ok := container.(map[string]interface{}); ok {
+				changed = rewrite(containerMap) || changed
+			}
+		}
+		document = containers
+	} else {
+		var container map[string]interface{}
+		if err := decoder.Decode(&container); err != nil {
+			return nil, err
+		}
+		changed = rewrite(container)
+		document = container
+	}
+
+	if !changed {
+		return nil, nil
+	}
+	return json.Marshal(document)
+}
+
+// reverseSyncPaths restores the local paths in HostConfig.Binds,
+// HostConfig.Mounts[].Source and Mounts[].Source of a container object
+func reverseSyncPaths(container map[string]interface{}) bool {
+	changed := false
+
+	if hostConfig, ok := container["HostConfig"].(map[string]interface{}); ok {
+		if binds, ok := hostConfig["Binds"].([]interface{}); ok {
+			for i, bindIface := range binds {
+				bind, ok := bindIface.(string)
+				if !ok {
+					continue
+				}
+				spec, err := mutagen_bridge.ParseBindSpec(bind)
+				if err != nil || !spec.IsBind() {
+					continue
+				}
+				if localPath, ok := mutagen_bridge.LocalPathFromRemote(spec.Source); ok {
+					spec.Source = localPath
+					binds[i] = spec.String()
+					changed = true
+				}
+			}
+		}
+
+		changed = reverseMountSources(hostConfig["Mounts"]) || changed
+	}
+
+	changed = reverseMountSources(container["Mounts"]) || changed
+
+	return changed
+}
+
+// reverseMountSources restores the local paths in the Source field of bind
+// mount objects
+func reverseMountSources(mountsIface interface{}) bool {
+	mounts, ok := mountsIface.([]interface{})
+	if !ok {
+		return false
+	}
+
+	changed := false
+	for _, mountIface := range mounts {
+		mount, ok := mountIface.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if mountType, _ := mount["Type"].(string); mountType != mutagen_bridge.MountTypeBind {
+			continue
+		}
+		source, _ := mount["Source"].(string)
+		if localPath, ok := mutagen_bridge.LocalPathFromRemote(source); ok {
+			mount["Source"] = localPath
+			changed = true
+		}
+	}
+	return changed
+}
+
+// restoreLocalPorts replaces the remote host ports in NetworkSettings.Ports of
+// an inspect object, or in Ports[].PublicPort of a list entry, with the local
+// ports they are forwarded on
+func (p *DockerAPIProxy) restoreLocalPorts(container map[string]interface{}) bool {
+	containerID, _ := container["Id"].(string)
+	if containerID == "" {
+		return false
+	}
+	localPorts := p.portForwardMgr.LocalPorts(containerID)
+	if len(localPorts) == 0 {
+		return false
+	}
+
+	changed := false
+
+	// Inspect format: {"NetworkSettings": {"Ports": {"80/tcp": [{"HostIp": "0.0.0.0", "HostPort": "32768"}]}}}
+	if networkSettings, ok := container["NetworkSettings"].(map[string]interface{}); ok {
+		if ports, ok := networkSettings["Ports"].(map[string]interface{}); ok {
+			for containerPort, bindingsIface := range ports {
+				protocol := "tcp"
+				if _, proto, found := strings.Cut(containerPort, "/"); found {
+					protocol = proto
+				}
+				bindings, _ := bindingsIface.([]interface{})
+				for _, bindingIface := range bindings {
+					binding, ok := bindingIface.(map[string]interface{})
+					if !ok {
+						continue
+					}
+					hostPort, _ := binding["HostPort"].(string)
+					if localPort, ok := localPorts[hostPort+"/"+protocol]; ok {
+						binding["HostPort"] = localPort
+						changed = true
+					}
+				}
+			}
+		}
+	}
+
+	// List format: {"Ports": [{"PrivatePort": 80, "PublicPort": 32768, "Type": "tcp"}]}
+	if ports, ok := container["Ports"].([]interface{}); ok {
+		for _, portIface := range ports {
+			port, ok := portIface.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			publicPort, _ := port["PublicPort"].(json.Number)
+			protocol, _ := port["Type"].(string)
+			if localPort, ok := localPorts[publicPort.String()+"/"+protocol]; ok {
+				port["PublicPort"] = json.Number(localPort)
+				changed = true
+			}
+		}
+	}
+
+	return changed
+}
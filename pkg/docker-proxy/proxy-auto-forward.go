@@ -0,0 +1,153 @@
+package docker_proxy
+
+import (
+	"net"
+	"sort"
+	"time"
+
+	mutagen_bridge "github.com/teamycloud/tsctl/pkg/docker-proxy/mutagen-bridge"
+)
+
+const (
+	// autoForwardInterval is how often the guest is asked for the ports
+	// containers listen on
+	autoForwardInterval = 3 * time.Second
+
+	// listeningPortsPath is the guest endpoint reporting listening ports
+	listeningPortsPath = "/tinyscale/v1/host-exec/listening-ports"
+
+	// networkModeHost is the network mode of containers sharing the network
+	// namespace of the host
+	networkModeHost = "host"
+)
+
+// guestListeningPortsRequest is the payload of the listening-ports endpoint
+type guestListeningPortsRequest struct {
+	Pid int `json:"pid"`
+}
+
+// guestListeningPort is a port reported by the listening-ports endpoint
+type guestListeningPort struct {
+	Address string `json:"address"`
+	Port    int    `json:"port"`
+}
+
+// autoForwardTarget is where the listening ports of a container are found
+type autoForwardTarget struct {
+	pid         int    // Init process of the container
+	remoteHost  string // Address the ports are reached on from the remote host
+	hostNetwork bool   // Whether the container uses the host network
+}
+
+// autoForwardLoop forwards the ports running containers listen on until the
+// proxy is closed
+func (p *DockerAPIProxy) autoForwardLoop() {
+	ticker := time.NewTicker(autoForwardInterval)
+	defer ticker.Stop()
+
+	targets := make(map[string]*autoForwardTarget)
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.autoForward(targets)
+		}
+	}
+}
+
+// autoForward syncs the auto forwards of all running containers with the
+// ports they listen on. targets caches the inspected details of containers.
+func (p *DockerAPIProxy) autoForward(targets map[string]*autoForwardTarget) {
+	containers, err := p.listRunningContainers()
+	if err != nil {
+		p.logger.Debugf("Auto-forward failed to list running containers: %v", err)
+		return
+	}
+
+	for containerID := range targets {
+		if _, running := containers[containerID]; !running {
+			delete(targets, containerID)
+		}
+	}
+
+	for containerID, info := range containers {
+		if info.Labels[mutagen_bridge.AutoForwardLabel] == "false" {
+			continue
+		}
+
+		target, ok := targets[containerID]
+		if !ok {
+			if target, err = p.inspectAutoForwardTarget(containerID); err != nil {
+				p.logger.Debugf("Auto-forward skipped container %s: %v", containerID, err)
+				continue
+			}
+			targets[containerID] = target
+		}
+		if target.remoteHost == "" {
+			// No network to reach the container on
+			continue
+		}
+
+		var listening []guestListeningPort
+		if err := p.guestRequest(listeningPortsPath, &guestListeningPortsRequest{Pid: target.pid}, &listening); err != nil {
+			p.logger.Debugf("Auto-forward failed to list listening ports of %s: %v", containerID, err)
+			// The container may have been restarted with another pid
+			delete(targets, containerID)
+			continue
+		}
+
+		ports := make([]int, 0, len(listening))
+		for _, port := range listening {
+			if target.reachable(port.Address) {
+				ports = append(ports, port.Port)
+			}
+		}
+
+		p.portForwardMgr.SyncAutoForwards(containerID, mutagen_bridge.ContainerMeta{
+			Name:   info.Name,
+			Labels: info.Labels,
+		}, target.remoteHost, ports, p.promptIdentifier)
+	}
+}
+
+// inspectAutoForwardTarget finds the init process and the address of a
+// container
+func (p *DockerAPIProxy) inspectAutoForwardTarget(containerID string) (*autoForwardTarget, error) {
+	inspect, err := p.inspectContainer(containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	target := &autoForwardTarget{pid: inspect.State.Pid}
+	if inspect.HostConfig.NetworkMode == networkModeHost {
+		target.remoteHost = "localhost"
+		target.hostNetwork = true
+		return target, nil
+	}
+
+	// Containers on several networks are reached on the first one
+	networks := make([]string, 0, len(inspect.NetworkSettings.Networks))
+	for name := range inspect.NetworkSettings.Networks {
+		networks = append(networks, name)
+	}
+	sort.Strings(networks)
+	for _, name := range networks {
+		if address := inspect.NetworkSettings.Networks[name].IPAddress; address != "" {
+			target.remoteHost = address
+			break
+		}
+	}
+	return target, nil
+}
+
+// reachable checks if a port listening on address can be reached from the
+// remote host. Loopback addresses inside the network namespace of a container
+// are not reachable from outside of it.
+func (t *autoForwardTarget) reachable(address string) bool {
+	if t.hostNetwork {
+		return true
+	}
+	ip := net.ParseIP(address)
+	return ip != nil && (ip.IsUnspecified() || ip.String() == t.remoteHost)
+}
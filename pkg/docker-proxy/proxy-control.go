@@ -0,0 +1,157 @@
+package docker_proxy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	mutagen_bridge "github.com/teamycloud/tsctl/pkg/docker-proxy/mutagen-bridge"
+	"github.com/teamycloud/tsctl/pkg/docker-proxy/types"
+)
+
+// ListenAndServeControl serves the daemon control API, used by tsctl
+// commands to query the running daemon, on a Unix domain socket
+func (p *DockerAPIProxy) ListenAndServeControl(socketPath string) error {
+	// Remove a socket left behind by a daemon that did not shut down cleanly,
+	// the daemon lock guarantees no other daemon is using it
+	if err := os.Remove(socketPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("unable to remove stale control socket: %w", err)
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("unable to listen on control socket: %w", err)
+	}
+
+	p.logger.Debugf("Control API listening on %s", socketPath)
+
+	if err := p.controlServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// newControlServer creates the server of the daemon control API
+func (p *DockerAPIProxy) newControlServer() *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tinyscale/v1/daemon/status", p.handleDaemonStatus)
+	mux.HandleFunc("GET /tinyscale/v1/daemon/sessions", p.handleListSessions)
+	mux.HandleFunc("DELETE /tinyscale/v1/daemon/sessions/{session}", p.handleTerminateSession)
+	mux.HandleFunc("POST /tinyscale/v1/daemon/port-forwards", p.handleCreatePortForward)
+	mux.HandleFunc("POST /tinyscale/v1/daemon/syncs", p.handleCreateSync)
+	return &http.Server{Handler: mux}
+}
+
+// handleDaemonStatus reports the transport and the port forwards of the daemon
+func (p *DockerAPIProxy) handleDaemonStatus(w http.ResponseWriter, r *http.Request) {
+	status := types.DaemonStatus{
+		PID:             os.Getpid(),
+		TransportType:   p.cfg.TransportType,
+		ListenAddr:      p.cfg.ListenAddr,
+		PortForwards:    p.portForwardMgr.Status(),
+		ReverseForwards: p.portForwardMgr.ReverseStatus(),
+	}
+	if p.cfg.TransportType == types.TransportSSH {
+		status.Remote = fmt.Sprintf("%s@%s", p.cfg.SSHUser, p.cfg.SSHHost)
+	} else {
+		status.Remote = p.cfg.TSTunnelServer
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&status); err != nil {
+		p.logger.Debugf("Failed to write daemon status: %v", err)
+	}
+}
+
+// handleListSessions reports the mutagen sessions of the daemon
+func (p *DockerAPIProxy) handleListSessions(w http.ResponseWriter, r *http.Request) {
+	sessions, err := p.portForwardMgr.Sessions()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	syncSessions, err := p.fileSyncMgr.Sessions()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sessions = append(sessions, syncSessions...)
+	p.writeControlResponse(w, http.StatusOK, sessions)
+}
+
+// handleTerminateSession terminates an ad-hoc session by identifier or name
+func (p *DockerAPIProxy) handleTerminateSession(w http.ResponseWriter, r *http.Request) {
+	spec := r.PathValue("session")
+	found, err := p.portForwardMgr.TerminateAdHocForwards(spec)
+	if err == nil && !found {
+		found, err = p.fileSyncMgr.TerminateAdHocSyncs(spec)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !found {
+		http.Error(w, fmt.Sprintf("no session matches %s", spec), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleCreatePortForward creates an ad-hoc port forward
+func (p *DockerAPIProxy) handleCreatePortForward(w http.ResponseWriter, r *http.Request) {
+	var req types.PortForwardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	session, err := p.portForwardMgr.CreateAdHocForward(req, p.promptIdentifier)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	p.writeControlResponse(w, http.StatusCreated, session)
+}
+
+// handleCreateSync creates an ad-hoc synchronization of a local directory
+func (p *DockerAPIProxy) handleCreateSync(w http.ResponseWriter, r *http.Request) {
+	var req types.SyncRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	mount, err := mutagen_bridge.AdHocSyncMount(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := p.fileSyncMgr.CheckAdHocSync(mount); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	mounts := &mutagen_bridge.ContainerMounts{Mounts: []*mutagen_bridge.BindMount{mount}}
+	if err := p.createRemoteMountDirectories(mounts); err != nil {
+		http.Error(w, fmt.Sprintf("unable to create remote directory: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	session, err := p.fileSyncMgr.CreateAdHocSync(mount, p.promptIdentifier)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	p.writeControlResponse(w, http.StatusCreated, session)
+}
+
+// writeControlResponse writes a JSON response of the control API
+func (p *DockerAPIProxy) writeControlResponse(w http.ResponseWriter, statusCode int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		p.logger.Debugf("Failed to write control response: %v", err)
+	}
+}
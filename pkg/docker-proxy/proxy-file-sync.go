@@ -9,39 +9,26 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"path/filepath"
-	"strings"
+	"path"
 
 	"github.com/teamycloud/tsctl/pkg/docker-proxy/mutagen-bridge"
 	"github.com/teamycloud/tsctl/pkg/docker-proxy/types"
 	ts_tunnel "github.com/teamycloud/tsctl/pkg/ts-tunnel"
+	"github.com/teamycloud/tsctl/pkg/version"
 )
 
 // rewriteBindMount rewrites a bind mount string by replacing the host path with a remote sync path
-// Input format: "source:target[:ro]"
-// Output format: "/opt/container-mount-sync/target:target[:ro]"
-func rewriteBindMount(bind string, basePath string) string {
-	parts := strings.Split(bind, ":")
-	if len(parts) < 2 {
-		// Invalid format, return as-is
+// Input format: "source:target[:options]"
+// Output format: "/opt/container-mount-sync/source:target[:options]"
+// Volumes and specs that cannot be parsed are returned as-is.
+func rewriteBindMount(bind string) string {
+	spec, err := mutagen_bridge.ParseBindSpec(bind)
+	if err != nil || !spec.IsBind() {
 		return bind
 	}
 
-	// parts[0] = source (host path)
-	// parts[1] = target (container path)
-	// parts[2+] = optional flags (e.g., "ro")
-
-	source := parts[0]
-	target := parts[1]
-	newSource := fmt.Sprintf("%s%s", basePath, source)
-
-	if len(parts) == 2 {
-		return fmt.Sprintf("%s:%s", newSource, target)
-	} else {
-		// Preserve any additional flags
-		flags := strings.Join(parts[2:], ":")
-		return fmt.Sprintf("%s:%s:%s", newSource, target, flags)
-	}
+	spec.Source = mutagen_bridge.RemotePath(spec.Source)
+	return spec.String()
 }
 
 // createRemoteMountDirectories creates all mount directories on the remote host
@@ -56,9 +43,8 @@ func (p *DockerAPIProxy) createRemoteMountDirectories(mounts *mutagen_bridge.Con
 		// Get the original local path
 		localPath := mount.HostPath
 
-		// Determine the remote path based on the sync base path
-		// The remote path is: SyncBasePath + localPath
-		remotePath := fmt.Sprintf("%s%s", mutagen_bridge.SyncBasePath, localPath)
+		// The remote path is under the sync base path
+		remotePath := mount.RemotePath
 
 		// Check if the local path is a directory or file
 		info, err := os.Stat(localPath)
@@ -74,7 +60,7 @@ func (p *DockerAPIProxy) createRemoteMountDirectories(mounts *mutagen_bridge.Con
 			log.Printf("Local path %s is a directory, will create %s on remote", localPath, dirToCreate)
 		} else {
 			// If it's a file, create its parent directory on remote
-			dirToCreate = filepath.Dir(remotePath)
+			dirToCreate = path.Dir(remotePath)
 			log.Printf("Local path %s is a file, will create parent directory %s on remote", localPath, dirToCreate)
 		}
 
@@ -118,7 +104,7 @@ func (p *DockerAPIProxy) createDirsByTsTunnel(dirsToCreate []string) error {
 		return fmt.Errorf("failed to create directories on remote host: %v", err)
 	}
 	req.Host = ts_tunnel.URLHostName(p.tsTunnelOpts.ServerAddr)
-	req.Header.Set("User-Agent", "tsctl/1.0.0")
+	req.Header.Set("User-Agent", version.UserAgent())
 	req.Header.Set("Content-Type", "application/json")
 
 	reqBody, _ := json.Marshal(dirsToCreate)
@@ -0,0 +1,114 @@
+package docker_proxy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/teamycloud/tsctl/pkg/docker-proxy/types"
+	ts_tunnel "github.com/teamycloud/tsctl/pkg/ts-tunnel"
+	"github.com/teamycloud/tsctl/pkg/version"
+)
+
+// dialGuest opens an upgraded stream to an endpoint of the guest agent
+func (p *DockerAPIProxy) dialGuest(requestPath string, payload any) (net.Conn, error) {
+	conn, reader, resp, err := p.sendGuestRequest(requestPath, payload, true)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		conn.Close()
+		return nil, fmt.Errorf("guest request %s returned status %d: %s", requestPath, resp.StatusCode, string(body))
+	}
+
+	return &bufferedConn{Conn: conn, reader: reader}, nil
+}
+
+// guestRequest sends a request to an endpoint of the guest agent and decodes
+// its JSON response into out
+func (p *DockerAPIProxy) guestRequest(requestPath string, payload any, out any) error {
+	conn, _, resp, err := p.sendGuestRequest(requestPath, payload, false)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("guest request %s returned status %d: %s", requestPath, resp.StatusCode, string(body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode guest response: %w", err)
+	}
+	return nil
+}
+
+// sendGuestRequest posts a JSON payload to an endpoint of the guest agent.
+// With ts-tunnel, the connector routes the request to the guest by its path,
+// with SSH the guest is reached at GuestAddr through the SSH connection.
+func (p *DockerAPIProxy) sendGuestRequest(requestPath string, payload any, upgrade bool) (net.Conn, *bufio.Reader, *http.Response, error) {
+	var conn net.Conn
+	var host string
+	var err error
+	if p.cfg.TransportType == types.TransportSSH {
+		conn, err = p.sshClient.Client().Dial("tcp", p.cfg.GuestAddr)
+		host = p.cfg.GuestAddr
+	} else {
+		conn, err = p.dialRemote()
+		host = ts_tunnel.URLHostName(p.tsTunnelOpts.ServerAddr)
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to dial guest agent: %w", err)
+	}
+
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		conn.Close()
+		return nil, nil, nil, fmt.Errorf("failed to marshal guest request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", requestPath, bytes.NewReader(reqBody))
+	if err != nil {
+		conn.Close()
+		return nil, nil, nil, fmt.Errorf("failed to create guest request: %w", err)
+	}
+	req.Host = host
+	req.Header.Set("User-Agent", version.UserAgent())
+	req.Header.Set("Content-Type", "application/json")
+	if upgrade {
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "tcp")
+	}
+
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, nil, nil, fmt.Errorf("failed to send guest request: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, nil, nil, fmt.Errorf("failed to read guest response: %w", err)
+	}
+	return conn, reader, resp, nil
+}
+
+// bufferedConn is a net.Conn whose reads go through a bufio.Reader that may
+// already hold data read past an HTTP response
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// Read reads from the buffered reader
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	mutagen_bridge "github.com/teamycloud/tsctl/pkg/docker-proxy/mutagen-bridge"
+	"github.com/teamycloud/tsctl/pkg/version"
 )
 
 // getContainerID fetches the full container ID from Docker API given a name or short ID
@@ -31,7 +32,7 @@ func (p *DockerAPIProxy) getContainerID(apiVersion string, containerIDOrName str
 		return ""
 	}
 	req.Host = "docker.example.com"
-	req.Header.Set("User-Agent", "tsctl/1.0.0")
+	req.Header.Set("User-Agent", version.UserAgent())
 
 	// Send the request
 	if err := req.Write(conn); err != nil {
@@ -74,70 +75,86 @@ func (p *DockerAPIProxy) getContainerID(apiVersion string, containerIDOrName str
 	return inspectResp.Id
 }
 
-// isContainerRunning checks if a container exists and is running on the remote host
-func (p *DockerAPIProxy) isContainerRunning(containerID string) bool {
-	// Create a new connection to query container state
+// ContainerInspect holds the parts of the container inspect response used by the proxy
+type ContainerInspect struct {
+	Id     string `json:"Id"`
+	Name   string `json:"Name"`
+	Config struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	State struct {
+		Running bool   `json:"Running"`
+		Status  string `json:"Status"`
+		Pid     int    `json:"Pid"`
+	} `json:"State"`
+	HostConfig struct {
+		NetworkMode string `json:"NetworkMode"`
+	} `json:"HostConfig"`
+	NetworkSettings struct {
+		Ports map[string][]struct {
+			HostIp   string `json:"HostIp"`
+			HostPort string `json:"HostPort"`
+		} `json:"Ports"`
+		Networks map[string]struct {
+			IPAddress string `json:"IPAddress"`
+		} `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// PublishedPorts returns the host ports Docker published for each container port
+func (c *ContainerInspect) PublishedPorts() map[string][]string {
+	ports := make(map[string][]string)
+	for containerPort, bindings := range c.NetworkSettings.Ports {
+		for _, binding := range bindings {
+			if binding.HostPort != "" {
+				ports[containerPort] = append(ports[containerPort], binding.HostPort)
+			}
+		}
+	}
+	return ports
+}
+
+// inspectContainer fetches the details of a container from the remote host
+func (p *DockerAPIProxy) inspectContainer(containerID string) (*ContainerInspect, error) {
 	conn, err := p.dialRemote()
 	if err != nil {
-		log.Printf("Failed to dial remote Docker for container check: %v", err)
-		return false
+		return nil, fmt.Errorf("failed to dial remote Docker: %w", err)
 	}
 	defer conn.Close()
 
-	// Build the inspect request
 	req, err := http.NewRequest("GET", fmt.Sprintf("/containers/%s/json", containerID), nil)
 	if err != nil {
-		log.Printf("Failed to create inspect request: %v", err)
-		return false
+		return nil, fmt.Errorf("failed to create inspect request: %w", err)
 	}
 	req.Host = "docker.example.com"
-	req.Header.Set("User-Agent", "tsctl/1.0.0")
+	req.Header.Set("User-Agent", version.UserAgent())
 
-	// Send the request
 	if err := req.Write(conn); err != nil {
-		log.Printf("Failed to send inspect request: %v", err)
-		return false
+		return nil, fmt.Errorf("failed to send inspect request: %w", err)
 	}
 
-	// Read the response
 	reader := bufio.NewReader(conn)
 	resp, err := http.ReadResponse(reader, req)
 	if err != nil {
-		log.Printf("Failed to read inspect response: %v", err)
-		return false
+		return nil, fmt.Errorf("failed to read inspect response: %w", err)
 	}
 	defer resp.Body.Close()
 
-	// Check response status
-	if resp.StatusCode != 200 {
-		log.Printf("Container %s does not exist (status %d)", containerID, resp.StatusCode)
-		return false
-	}
-
-	// Parse the response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("Failed to read inspect response body: %v", err)
-		return false
+		return nil, fmt.Errorf("failed to read inspect response body: %w", err)
 	}
 
-	// Parse JSON to get container state
-	var inspectResp struct {
-		State struct {
-			Running bool   `json:"Running"`
-			Status  string `json:"Status"`
-		} `json:"State"`
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("container inspect returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	if err := json.Unmarshal(body, &inspectResp); err != nil {
-		log.Printf("Failed to parse inspect response: %v", err)
-		return false
+	var inspect ContainerInspect
+	if err := json.Unmarshal(body, &inspect); err != nil {
+		return nil, fmt.Errorf("failed to parse inspect response: %w", err)
 	}
 
-	isRunning := inspectResp.State.Running
-	log.Printf("Container %s state: Running=%v, Status=%s", containerID, inspectResp.State.Running, inspectResp.State.Status)
-
-	return isRunning
+	return &inspect, nil
 }
 
 // isContainerStopped checks if a container is actually stopped by inspecting its state
@@ -209,6 +226,8 @@ func (p *DockerAPIProxy) isContainerStopped(containerID string) bool {
 // ContainerInfo holds container details including bindings
 type ContainerInfo struct {
 	ID           string
+	Name         string
+	Labels       map[string]string
 	PortBindings map[string][]string
 	Mounts       []string
 }
@@ -228,7 +247,7 @@ func (p *DockerAPIProxy) listRunningContainers() (map[string]*ContainerInfo, err
 		return nil, fmt.Errorf("failed to create container list request: %w", err)
 	}
 	req.Host = "docker.example.com"
-	req.Header.Set("User-Agent", "tsctl/1.0.0")
+	req.Header.Set("User-Agent", version.UserAgent())
 
 	// Send the request
 	if err := req.Write(conn); err != nil {
@@ -256,8 +275,10 @@ func (p *DockerAPIProxy) listRunningContainers() (map[string]*ContainerInfo, err
 
 	// Parse JSON to get container details
 	var containers []struct {
-		Id    string `json:"Id"`
-		Ports []struct {
+		Id     string            `json:"Id"`
+		Names  []string          `json:"Names"`
+		Labels map[string]string `json:"Labels"`
+		Ports  []struct {
 			IP          string `json:"IP"`
 			PrivatePort int    `json:"PrivatePort"`
 			PublicPort  int    `json:"PublicPort"`
@@ -281,9 +302,13 @@ func (p *DockerAPIProxy) listRunningContainers() (map[string]*ContainerInfo, err
 	for _, container := range containers {
 		info := &ContainerInfo{
 			ID:           container.Id,
+			Labels:       container.Labels,
 			PortBindings: make(map[string][]string),
 			Mounts:       make([]string, 0),
 		}
+		if len(container.Names) > 0 {
+			info.Name = strings.TrimPrefix(container.Names[0], "/")
+		}
 
 		// Extract port bindings
 		for _, port := range container.Ports {
@@ -299,18 +324,18 @@ func (p *DockerAPIProxy) listRunningContainers() (map[string]*ContainerInfo, err
 			if mount.Type == "bind" {
 				// Mount source may have SyncBasePath prefix - extract original local path
 				localPath := mount.Source
-				if strings.HasPrefix(mount.Source, mutagen_bridge.SyncBasePath) {
-					localPath = strings.TrimPrefix(mount.Source, mutagen_bridge.SyncBasePath)
+				if original, ok := mutagen_bridge.LocalPathFromRemote(mount.Source); ok {
+					localPath = original
 				}
 
 				// Check if the local path exists on disk
 				if _, err := os.Stat(localPath); err == nil {
 					// Build mount string in the format: localPath:containerPath[:ro]
-					mountStr := fmt.Sprintf("%s:%s", localPath, mount.Destination)
+					spec := &mutagen_bridge.MountSpec{Type: mutagen_bridge.MountTypeBind, Source: localPath, Target: mount.Destination}
 					if !mount.RW {
-						mountStr = fmt.Sprintf("%s:ro", mountStr)
+						spec.Options = []string{"ro"}
 					}
-					info.Mounts = append(info.Mounts, mountStr)
+					info.Mounts = append(info.Mounts, spec.String())
 				}
 			}
 		}
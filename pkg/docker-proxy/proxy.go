@@ -18,7 +18,9 @@ import (
 	"github.com/mutagen-io/mutagen/pkg/synchronization"
 	mutagen_bridge "github.com/teamycloud/tsctl/pkg/docker-proxy/mutagen-bridge"
 	"github.com/teamycloud/tsctl/pkg/docker-proxy/types"
+	"github.com/teamycloud/tsctl/pkg/localdns"
 	ts_tunnel "github.com/teamycloud/tsctl/pkg/ts-tunnel"
+	"github.com/teamycloud/tsctl/pkg/vhost"
 )
 
 var (
@@ -33,6 +35,10 @@ var (
 	containerRemovePattern = regexp.MustCompile(`^/v[\d.]+/containers/([a-zA-Z0-9][a-zA-Z0-9_.-]+)$`)
 	// Pattern to match /containers/{id}/wait - long-running request that completes when container exits
 	containerWaitPattern = regexp.MustCompile(`^/v[\d.]+/containers/([a-f0-9]+)/wait`)
+	// Pattern to match /containers/{id}/json
+	containerInspectPattern = regexp.MustCompile(`^/v[\d.]+/containers/([a-zA-Z0-9][a-zA-Z0-9_.-]+)/json$`)
+	// Pattern to match /containers/json
+	containerListPattern = regexp.MustCompile(`^/v[\d.]+/containers/json$`)
 )
 
 // DockerAPIProxy implements a transparent TCP proxy that forwards connections
@@ -55,6 +61,11 @@ type DockerAPIProxy struct {
 
 	stopCh           chan struct{}
 	containerIDCache sync.Map // Cache for *http.Request -> containerID mapping
+	createWarnings   sync.Map // Cache for *http.Request -> []string of warnings found on create, e.g. port conflicts
+
+	controlServer *http.Server
+	dnsServer     *localdns.Server
+	httpRouter    *vhost.Router
 }
 
 // NewProxy creates a new TCP proxy instance and establishes SSH connection
@@ -105,13 +116,46 @@ func NewProxy(cfg types.Config, forwardingManager *forwarding.Manager, synchroni
 		fileSyncMgr:      fileSyncMgr,
 		stopCh:           make(chan struct{}),
 	}
+	proxy.controlServer = proxy.newControlServer()
+	if cfg.ContainerIPs && cfg.DNSListenAddr != "" {
+		proxy.dnsServer = localdns.NewServer(cfg.DNSListenAddr, mutagen_bridge.ContainerDomain,
+			portForwardMgr.LookupContainerIP, logger.Sublogger("dns"))
+	}
+	if cfg.HTTPRouterAddr != "" {
+		proxy.httpRouter = vhost.NewRouter(cfg.HTTPRouterAddr, cfg.HTTPRouterCertFile, cfg.HTTPRouterKeyFile,
+			portForwardMgr.HTTPRoutes, logger.Sublogger("http-router"))
+	}
+	portForwardMgr.SetGuestDialer(proxy.dialGuest)
+	fileSyncMgr.SetGuestDialer(proxy.dialGuest)
 
 	// Setup sessions for all currently running containers on the remote
 	go proxy.syncWithRunningContainers()
 
+	if cfg.AutoForward {
+		go proxy.autoForwardLoop()
+	}
+
 	return proxy, nil
 }
 
+// ListenAndServeDNS serves the names of containers with their own loopback
+// address, it returns immediately when ContainerIPs is disabled
+func (p *DockerAPIProxy) ListenAndServeDNS() error {
+	if p.dnsServer == nil {
+		return nil
+	}
+	return p.dnsServer.ListenAndServe()
+}
+
+// ListenAndServeHTTPRouter routes <service>.<project>.localhost to forwarded
+// container ports, it returns immediately when the router is disabled
+func (p *DockerAPIProxy) ListenAndServeHTTPRouter() error {
+	if p.httpRouter == nil {
+		return nil
+	}
+	return p.httpRouter.ListenAndServe()
+}
+
 // ListenAndServe starts the TCP proxy server
 func (p *DockerAPIProxy) ListenAndServe() error {
 	listener, err := net.Listen("tcp", p.cfg.ListenAddr)
@@ -181,8 +225,16 @@ func (p *DockerAPIProxy) handleConnection(clientConn net.Conn) {
 			return
 		}
 
-		// Handle container lifecycle operations BEFORE forwarding
-		p.handleContainerOperation(req)
+		// Handle container lifecycle operations BEFORE forwarding, the
+		// requests refused by the proxy are answered without reaching Docker
+		if refusal := p.handleContainerOperation(req); refusal != nil {
+			p.handleContainerOperationResponse(req, refusal)
+			if err := refusal.Write(clientConn); err != nil {
+				p.logger.Warnf("Failed to write refusal: %v", err)
+				return
+			}
+			continue
+		}
 
 		// Forward the request to remote Docker
 		if err := req.Write(remoteConn); err != nil {
@@ -275,12 +327,25 @@ func (p *DockerAPIProxy) dialRemote() (net.Conn, error) {
 	}
 }
 
-// handleContainerOperation handles container operations BEFORE forwarding the request
-func (p *DockerAPIProxy) handleContainerOperation(req *http.Request) {
+// handleContainerOperation handles container operations BEFORE forwarding the
+// request. It returns the response of the requests refused by the proxy,
+// which must not be forwarded.
+func (p *DockerAPIProxy) handleContainerOperation(req *http.Request) *http.Response {
 	p.logger.Tracef("Container operation request %s %s", req.Method, req.URL.Path)
 
 	if req.Method == http.MethodPost && containerCreatePattern.MatchString(req.URL.Path) {
-		p.handleContainerCreateRequest(req)
+		if refusal := p.handleContainerCreateRequest(req); refusal != nil {
+			return refusal
+		}
+	}
+
+	// Restore the socket forwards of restarted containers before Docker
+	// mounts the sockets
+	if req.Method == http.MethodPost && containerStartPattern.MatchString(req.URL.Path) {
+		matches := containerStartPattern.FindStringSubmatch(req.URL.Path)
+		if len(matches) > 1 {
+			p.portForwardMgr.SetupSocketForwards(matches[1], p.promptIdentifier)
+		}
 	}
 
 	// Handle container remove - resolve and cache container ID BEFORE the request is sent
@@ -308,6 +373,7 @@ func (p *DockerAPIProxy) handleContainerOperation(req *http.Request) {
 			}
 		}
 	}
+	return nil
 }
 
 // handleContainerOperationResponse handles container operations AFTER receiving the response
@@ -319,6 +385,15 @@ func (p *DockerAPIProxy) handleContainerOperationResponse(req *http.Request, res
 		p.handleContainerCreateResponse(req, resp)
 	}
 
+	// Handle container list and inspect - hide the sync base path from clients
+	if req.Method == http.MethodGet {
+		if containerListPattern.MatchString(req.URL.Path) {
+			p.handleContainerInspectResponse(resp, true)
+		} else if containerInspectPattern.MatchString(req.URL.Path) {
+			p.handleContainerInspectResponse(resp, false)
+		}
+	}
+
 	// Handle container start - setup port forwards
 	if req.Method == http.MethodPost && containerStartPattern.MatchString(req.URL.Path) {
 		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
@@ -353,6 +428,7 @@ func (p *DockerAPIProxy) handleContainerOperationResponse(req *http.Request, res
 			if cachedID, ok := p.containerIDCache.LoadAndDelete(req); ok {
 				if containerID, ok := cachedID.(string); ok {
 					p.logger.Tracef("Container remove detected, using cached ID: %s", containerID)
+					p.portForwardMgr.RemoveSocketForwards(containerID)
 					go p.teardownForContainer(containerID, "")
 				}
 			} else {
@@ -362,6 +438,7 @@ func (p *DockerAPIProxy) handleContainerOperationResponse(req *http.Request, res
 					containerIDOrName := matches[1]
 					if fullContainerIDPattern.MatchString(matches[1]) {
 						p.logger.Tracef("Container remove detected (no cache): %s", containerIDOrName)
+						p.portForwardMgr.RemoveSocketForwards(containerIDOrName)
 						go p.teardownForContainer(containerIDOrName, resp.Header["Api-Version"][0])
 					}
 				}
@@ -517,6 +594,14 @@ func (p *DockerAPIProxy) Close() error {
 		p.listener = nil
 	}
 
+	_ = p.controlServer.Close()
+	if p.dnsServer != nil {
+		_ = p.dnsServer.Close()
+	}
+	if p.httpRouter != nil {
+		_ = p.httpRouter.Close()
+	}
+
 	p.wg.Wait()
 
 	// Teardown all port forwards and file syncs
@@ -559,6 +644,14 @@ func (p *DockerAPIProxy) syncWithRunningContainers() {
 		existingSessions[containerID] = true
 	}
 
+	// Running containers keep the sync sessions they share with stopped ones
+	for containerID, containerInfo := range runningContainers {
+		if len(containerInfo.Mounts) > 0 {
+			syncOptions, _ := mutagen_bridge.ParseSyncLabels(containerInfo.Labels)
+			p.fileSyncMgr.RetainSyncs(containerID, containerInfo.Mounts, syncOptions)
+		}
+	}
+
 	// Teardown sessions for containers that are no longer running
 	for containerID := range existingSessions {
 		if _, stillRunning := runningContainers[containerID]; !stillRunning {
@@ -587,16 +680,28 @@ func (p *DockerAPIProxy) syncWithRunningContainers() {
 // setupSessionsIfRunning verifies the container is running and sets up sessions
 func (p *DockerAPIProxy) setupSessionsIfRunning(containerID string) {
 	// Verify the container is actually running by checking remote status
-	if !p.isContainerRunning(containerID) {
+	inspect, err := p.inspectContainer(containerID)
+	if err != nil {
+		p.logger.Debugf("Unable to inspect container %s, skipping session setup: %v", containerID, err)
+		return
+	}
+	if !inspect.State.Running {
 		p.logger.Debugf("Container %s is not running, skipping session setup", containerID)
 		return
 	}
 
 	p.logger.Debugf("Setting up sessions for running container %s", containerID)
 
+	// Forward the ports Docker assigned on start, e.g. for `-P` or `-p 80`
+	p.portForwardMgr.StorePublishedPorts(containerID, mutagen_bridge.ContainerMeta{
+		Name:   inspect.Name,
+		Labels: inspect.Config.Labels,
+	}, inspect.PublishedPorts())
+
 	if err := p.portForwardMgr.SetupForwards(containerID, p.promptIdentifier); err != nil {
 		p.logger.Warnf("Failed to setup port forwards for %s: %v", containerID, err)
 	}
+	p.portForwardMgr.SetupReverseForwards(containerID, inspect.Config.Labels, p.promptIdentifier)
 	if err := p.fileSyncMgr.SetupSyncs(containerID, p.promptIdentifier); err != nil {
 		p.logger.Warnf("Failed to setup file syncs for %s: %v", containerID, err)
 	}
@@ -610,18 +715,25 @@ func (p *DockerAPIProxy) storeContainerBindingsAndSetupSessions(containerID stri
 
 	// Store port bindings if any
 	if len(info.PortBindings) > 0 {
-		p.portForwardMgr.StorePortBindingsForContainer(containerID, info.PortBindings)
+		p.portForwardMgr.StorePortBindingsForContainer(containerID, mutagen_bridge.ContainerMeta{
+			Name:   info.Name,
+			Labels: info.Labels,
+		}, info.PortBindings)
 	}
 
 	// Store bind mounts if any
 	if len(info.Mounts) > 0 {
-		p.fileSyncMgr.StoreBindMountsForContainer(containerID, info.Mounts)
+		syncOptions, _ := mutagen_bridge.ParseSyncLabels(info.Labels)
+		p.fileSyncMgr.StoreBindMountsForContainer(containerID, info.Mounts, syncOptions)
+		p.portForwardMgr.StoreSocketForwardsForContainer(containerID, p.fileSyncMgr.SocketPaths(containerID))
 	}
 
 	// Now set up the sessions
 	if err := p.portForwardMgr.SetupForwards(containerID, p.promptIdentifier); err != nil {
 		p.logger.Warnf("Failed to setup port forwards for %s: %v", containerID, err)
 	}
+	p.portForwardMgr.SetupReverseForwards(containerID, info.Labels, p.promptIdentifier)
+	p.portForwardMgr.SetupSocketForwards(containerID, p.promptIdentifier)
 	if err := p.fileSyncMgr.SetupSyncs(containerID, p.promptIdentifier); err != nil {
 		p.logger.Warnf("Failed to setup file syncs for %s: %v", containerID, err)
 	}
@@ -0,0 +1,10 @@
+package types
+
+// HTTPRoute maps a local hostname to the forwarded port of a container
+type HTTPRoute struct {
+	Host          string // Hostname, e.g. "web.shop.localhost"
+	ContainerID   string
+	ContainerName string
+	ContainerPort string // Container port with protocol, e.g. "80/tcp"
+	Target        string // Local address the port is forwarded on, e.g. "localhost:8080"
+}
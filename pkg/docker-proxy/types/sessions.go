@@ -0,0 +1,37 @@
+package types
+
+// Kinds of the mutagen sessions reported by the sessions endpoint
+const (
+	SessionKindForward = "forward"
+	SessionKindSync    = "sync"
+)
+
+// PortForwardRequest asks the daemon for an ad-hoc forward of a local port
+// to a host and port reachable from the remote host, which is not tied to a
+// container
+type PortForwardRequest struct {
+	ListenAddress string `json:"listenAddress,omitempty"` // Local address to listen on, localhost when empty
+	LocalPort     string `json:"localPort"`
+	RemoteHost    string `json:"remoteHost"` // Host the remote host connects to, e.g. "localhost" or "db.internal"
+	RemotePort    string `json:"remotePort"`
+}
+
+// SessionStatus describes a mutagen session of the daemon
+type SessionStatus struct {
+	Identifier  string            `json:"identifier"`
+	Name        string            `json:"name"`
+	Kind        string            `json:"kind"`        // SessionKindForward or SessionKindSync
+	Source      string            `json:"source"`      // Forwarding source or synchronization alpha
+	Destination string            `json:"destination"` // Forwarding destination or synchronization beta
+	Labels      map[string]string `json:"labels,omitempty"`
+	AdHoc       bool              `json:"adHoc"` // Whether the session was created from the CLI rather than for a container
+	Status      string            `json:"status"`
+	LastError   string            `json:"lastError,omitempty"`
+}
+
+// SyncRequest asks the daemon for an ad-hoc synchronization of a local
+// directory to the remote host, which is not tied to a container
+type SyncRequest struct {
+	LocalPath  string `json:"localPath"`            // Absolute local directory
+	RemotePath string `json:"remotePath,omitempty"` // Absolute remote path, under SyncBasePath like bind mounts when empty
+}
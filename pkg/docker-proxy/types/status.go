@@ -0,0 +1,30 @@
+package types
+
+// DaemonStatus is reported by the status endpoint of the daemon control API
+type DaemonStatus struct {
+	PID             int                    `json:"pid"`
+	TransportType   TransportType          `json:"transportType"`
+	ListenAddr      string                 `json:"listenAddr"`
+	Remote          string                 `json:"remote"`
+	PortForwards    []PortForwardStatus    `json:"portForwards"`
+	ReverseForwards []ReverseForwardStatus `json:"reverseForwards"`
+}
+
+// PortForwardStatus describes a port forward of a container
+type PortForwardStatus struct {
+	ContainerID   string `json:"containerId"`
+	LocalAddress  string `json:"localAddress"`  // Local address and port, e.g. "localhost:8080"
+	RemotePort    string `json:"remotePort"`    // Port published on the remote host
+	ContainerPort string `json:"containerPort"` // Container port with protocol, e.g. "80/tcp"
+	Active        bool   `json:"active"`
+	Warning       string `json:"warning,omitempty"`
+}
+
+// ReverseForwardStatus describes a forward from the remote host to a local port
+type ReverseForwardStatus struct {
+	RemoteAddress string   `json:"remoteAddress"` // Address listened on on the remote host, e.g. "172.17.0.1:9000"
+	LocalPort     string   `json:"localPort"`
+	Containers    []string `json:"containers"` // IDs of the containers using the forward
+	Active        bool     `json:"active"`
+	Warning       string   `json:"warning,omitempty"`
+}
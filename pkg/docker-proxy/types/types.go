@@ -20,6 +20,7 @@ type Config struct {
 	SSHHost      string // SSH host and port (e.g., "remote.example.com:22")
 	SSHKeyPath   string // Path to SSH private key (e.g., "/home/user/.ssh/id_rsa")
 	RemoteDocker string // Remote Docker socket URL (e.g., "unix:///var/run/docker.sock" or "tcp://127.0.0.1:2375")
+	GuestAddr    string // Address of the guest agent on the remote host, used for UDP port forwards and bulk sync uploads (e.g., "127.0.0.1:2090")
 
 	// TS-Tunnel specific fields (used when TransportType == TransportTSTunnel)
 	TSTunnelServer   string // HTTPS endpoint (e.g., "containers.tinyscale.net:443")
@@ -27,4 +28,50 @@ type Config struct {
 	TSTunnelKeyFile  string // Path to client key file
 	TSTunnelCAFile   string // Path to CA certificate file (optional)
 	TSInsecure       bool   // Skip TLS Verify
+
+	// AllowPublicPorts honors non-loopback HostIp in port bindings, e.g.
+	// 0.0.0.0 to reach a forwarded port from other devices on the LAN
+	AllowPublicPorts bool
+
+	// RemapPorts forwards ports that are already in use locally on a free
+	// local port, instead of leaving them unforwarded
+	RemapPorts bool
+
+	// ContainerIPs gives each container its own loopback address from
+	// 127.77.0.0/16, so containers can publish the same port locally
+	ContainerIPs bool
+	// DNSListenAddr is where the DNS server resolving
+	// <container-name>.tinyscale.test listens when ContainerIPs is enabled
+	DNSListenAddr string
+
+	// HTTPRouterAddr is where the HTTP router serving
+	// <service>.<project>.localhost listens, empty to disable
+	HTTPRouterAddr string
+	// HTTPRouterCertFile and HTTPRouterKeyFile enable TLS on the HTTP router
+	HTTPRouterCertFile string
+	HTTPRouterKeyFile  string
+
+	// ReversePorts are the remote ports forwarded back to local ports for
+	// containers with the extra host host.docker.internal:host-gateway, in
+	// the format of the tinyscale.reverse-ports label, e.g. "9000,9229:19229"
+	ReversePorts string
+	// ReverseListenAddr is the remote address reverse forwards listen on,
+	// the address host-gateway resolves to
+	ReverseListenAddr string
+
+	// AutoForward forwards the TCP ports containers listen on, including
+	// ports that are not published such as the ones of host network
+	// containers and compose services that only expose them
+	AutoForward bool
+
+	// DependencyDirs are the subdirectories of bind mounts kept on remote
+	// named volumes instead of being synced, comma separated, e.g.
+	// "node_modules,.venv,target"
+	DependencyDirs string
+
+	// SyncMaxEntries and SyncMaxSize are the number of entries and total
+	// size above which the host path of a bind mount is refused instead of
+	// being synced, zero disables the limit
+	SyncMaxEntries uint64
+	SyncMaxSize    uint64
 }
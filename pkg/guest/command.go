@@ -56,34 +56,13 @@ func runCommand(cmdReq *CommandRequest, w http.ResponseWriter) {
 	// todo: 处理自动安装逻辑（从待运行的路径中，获取版本，并自动从指定的服务器下载安装）
 
 	// Hijack the connection to upgrade to TCP
-	hijacker, ok := w.(http.Hijacker)
-	if !ok {
-		http.Error(w, "Hijacking not supported", http.StatusInternalServerError)
-		return
-	}
-
-	conn, bufrw, err := hijacker.Hijack()
+	conn, _, err := upgradeToTCP(w)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Hijack failed: %v", err), http.StatusInternalServerError)
+		log.Printf("Failed to upgrade connection: %v", err)
 		return
 	}
 	defer conn.Close()
 
-	// Send 101 Switching Protocols response
-	response := "HTTP/1.1 101 Switching Protocols\r\n" +
-		"Upgrade: tcp\r\n" +
-		"Connection: Upgrade\r\n" +
-		"\r\n"
-
-	if _, err := bufrw.WriteString(response); err != nil {
-		log.Printf("Failed to write upgrade response: %v", err)
-		return
-	}
-	if err := bufrw.Flush(); err != nil {
-		log.Printf("Failed to flush upgrade response: %v", err)
-		return
-	}
-
 	// Register the connection
 	processRegistry.AddConnection(conn)
 	defer processRegistry.RemoveConnection(conn)
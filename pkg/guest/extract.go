@@ -0,0 +1,243 @@
+package guest
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ExtractRequest selects the directory a gzip compressed tar stream is
+// extracted into, and the modes of the extracted entries
+type ExtractRequest struct {
+	Path          string `json:"path"`
+	FileMode      uint32 `json:"fileMode"`
+	DirectoryMode uint32 `json:"directoryMode"`
+}
+
+// ExtractResult is written back once the stream is extracted
+type ExtractResult struct {
+	Entries int    `json:"entries"`
+	Bytes   int64  `json:"bytes"`
+	Error   string `json:"error,omitempty"`
+}
+
+// handleExtract upgrades the connection and extracts the gzip compressed tar
+// stream that follows into a new directory, or one holding only directories.
+// It is used to populate the remote directory of a sync session in bulk
+// before the session starts.
+func handleExtract(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Check for Upgrade header
+	if strings.ToLower(r.Header.Get("Upgrade")) != "tcp" {
+		http.Error(w, "Upgrade: tcp header required", http.StatusBadRequest)
+		return
+	}
+
+	var req ExtractRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if !filepath.IsAbs(req.Path) || filepath.Clean(req.Path) == "/" {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+	if req.FileMode == 0 {
+		req.FileMode = 0644
+	}
+	if req.DirectoryMode == 0 {
+		req.DirectoryMode = 0755
+	}
+
+	// Never extract over existing content, the sync session reconciles it
+	if found, err := hasFiles(req.Path); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to read directory: %v", err), http.StatusInternalServerError)
+		return
+	} else if found {
+		http.Error(w, "Directory is not empty", http.StatusConflict)
+		return
+	}
+
+	conn, reader, err := upgradeToTCP(w)
+	if err != nil {
+		log.Printf("Failed to upgrade connection: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// Register the connection
+	processRegistry.AddConnection(conn)
+	defer processRegistry.RemoveConnection(conn)
+
+	result, err := extractTarGz(reader, req)
+	if err != nil {
+		log.Printf("Failed to extract into %s: %v", req.Path, err)
+		result.Error = err.Error()
+	}
+	if err := json.NewEncoder(conn).Encode(result); err != nil {
+		log.Printf("Failed to send extract result: %v", err)
+	}
+}
+
+// errFileFound stops the walk of hasFiles
+var errFileFound = errors.New("file found")
+
+// hasFiles returns whether a directory holds anything but directories, such
+// as the mount points of nested bind mounts created before the extraction
+func hasFiles(dir string) (bool, error) {
+	err := filepath.WalkDir(dir, func(_ string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return errFileFound
+		}
+		return nil
+	})
+	if errors.Is(err, errFileFound) {
+		return true, nil
+	} else if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// extractTarGz extracts the directories and regular files of a gzip
+// compressed tar stream under req.Path. Entries are never written outside of
+// req.Path.
+func extractTarGz(r io.Reader, req ExtractRequest) (ExtractResult, error) {
+	var result ExtractResult
+
+	root := filepath.Clean(req.Path)
+	if err := os.MkdirAll(root, os.FileMode(req.DirectoryMode)); err != nil {
+		return result, fmt.Errorf("failed to create directory: %w", err)
+	}
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return result, fmt.Errorf("failed to resolve directory: %w", err)
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return result, fmt.Errorf("invalid gzip stream: %w", err)
+	}
+	defer gz.Close()
+	// The stream is followed by nothing but the result, stop at the end of it
+	gz.Multistream(false)
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return result, fmt.Errorf("invalid tar stream: %w", err)
+		}
+
+		target, err := extractTarget(root, header.Name)
+		if err != nil {
+			return result, err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := extractDirectory(target, req.DirectoryMode); err != nil {
+				return result, err
+			}
+		case tar.TypeReg:
+			// Executability is the only permission propagated by the sync
+			mode := req.FileMode
+			if header.Mode&0111 != 0 {
+				mode |= (mode & 0444) >> 2
+			}
+			n, err := extractFile(resolvedRoot, target, tr, mode, req.DirectoryMode)
+			if err != nil {
+				return result, err
+			}
+			result.Bytes += n
+		default:
+			// Other entries, e.g. symbolic links, are left to the sync session
+			continue
+		}
+		if !header.ModTime.IsZero() {
+			_ = os.Chtimes(target, time.Now(), header.ModTime)
+		}
+		result.Entries++
+	}
+
+	// Consume the gzip trailer, which verifies the checksum
+	if _, err := io.Copy(io.Discard, gz); err != nil {
+		return result, fmt.Errorf("invalid gzip stream: %w", err)
+	}
+	return result, nil
+}
+
+// extractTarget returns the path of a tar entry under root, refusing the
+// entries escaping it
+func extractTarget(root, name string) (string, error) {
+	target := filepath.Join(root, filepath.FromSlash(name))
+	if target != root && !strings.HasPrefix(target, root+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid tar entry %q", name)
+	}
+	return target, nil
+}
+
+// extractDirectory creates a directory with the given mode regardless of the
+// umask
+func extractDirectory(target string, mode uint32) error {
+	if err := os.MkdirAll(target, os.FileMode(mode)); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.Chmod(target, os.FileMode(mode)); err != nil {
+		return fmt.Errorf("failed to set directory mode: %w", err)
+	}
+	return nil
+}
+
+// extractFile writes a regular file with the given mode regardless of the
+// umask. The parent directories are created if needed, they must not resolve
+// outside of resolvedRoot.
+func extractFile(resolvedRoot, target string, content io.Reader, mode, directoryMode uint32) (int64, error) {
+	parent := filepath.Dir(target)
+	if err := os.MkdirAll(parent, os.FileMode(directoryMode)); err != nil {
+		return 0, fmt.Errorf("failed to create directory: %w", err)
+	}
+	resolved, err := filepath.EvalSymlinks(parent)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve directory: %w", err)
+	}
+	if resolved != resolvedRoot && !strings.HasPrefix(resolved, resolvedRoot+string(filepath.Separator)) {
+		return 0, fmt.Errorf("invalid tar entry %s, its directory resolves outside of %s", target, resolvedRoot)
+	}
+
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, os.FileMode(mode))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	n, err := io.Copy(file, content)
+	if err != nil {
+		file.Close()
+		return n, fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	if err := file.Chmod(os.FileMode(mode)); err != nil {
+		file.Close()
+		return n, fmt.Errorf("failed to set file mode: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return n, fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,102 @@
+package guest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WriteFileRequest describes the file content following the request
+type WriteFileRequest struct {
+	Path string `json:"path"`
+	Mode uint32 `json:"mode"`
+	Size int64  `json:"size"`
+}
+
+// WriteFileResult is written back once the file is written
+type WriteFileResult struct {
+	Error string `json:"error,omitempty"`
+}
+
+// handleWriteFile upgrades the connection and writes the Size bytes that
+// follow to a file. Existing files are written in place, so that containers
+// bind mounting them see the new content.
+func handleWriteFile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Check for Upgrade header
+	if strings.ToLower(r.Header.Get("Upgrade")) != "tcp" {
+		http.Error(w, "Upgrade: tcp header required", http.StatusBadRequest)
+		return
+	}
+
+	var req WriteFileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if !filepath.IsAbs(req.Path) || req.Size < 0 {
+		http.Error(w, "Invalid path or size", http.StatusBadRequest)
+		return
+	}
+	if req.Mode == 0 {
+		req.Mode = 0644
+	}
+
+	conn, reader, err := upgradeToTCP(w)
+	if err != nil {
+		log.Printf("Failed to upgrade connection: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// Register the connection
+	processRegistry.AddConnection(conn)
+	defer processRegistry.RemoveConnection(conn)
+
+	var result WriteFileResult
+	if err := writeFileInPlace(req, io.LimitReader(reader, req.Size)); err != nil {
+		log.Printf("Failed to write %s: %v", req.Path, err)
+		result.Error = err.Error()
+	}
+	if err := json.NewEncoder(conn).Encode(result); err != nil {
+		log.Printf("Failed to send write result: %v", err)
+	}
+}
+
+// writeFileInPlace truncates and writes a file rather than replacing it,
+// which would leave bind mounts on the previous file
+func writeFileInPlace(req WriteFileRequest, content io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(req.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	file, err := os.OpenFile(req.Path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(req.Mode))
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	n, err := io.Copy(file, content)
+	if err == nil && n != req.Size {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := file.Chmod(os.FileMode(req.Mode)); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to set file mode: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
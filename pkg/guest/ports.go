@@ -0,0 +1,181 @@
+package guest
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// tcpListenState is the state of listening sockets in /proc/net/tcp
+const tcpListenState = "0A"
+
+// ListeningPortsRequest selects the process whose listening TCP sockets are
+// reported, usually the init process of a container. Pid 0 reports all the
+// listening sockets of the network namespace of the guest.
+type ListeningPortsRequest struct {
+	Pid int `json:"pid"`
+}
+
+// ListeningPort is a TCP socket in the LISTEN state
+type ListeningPort struct {
+	Address string `json:"address"` // Listening address, e.g. "0.0.0.0", "::" or "127.0.0.1"
+	Port    int    `json:"port"`
+}
+
+// handleListeningPorts reports the listening TCP sockets in the network
+// namespace of a process. Sockets are limited to the ones opened by the
+// processes sharing its PID namespace, which tells the ports of a container
+// apart from the ones of the host for containers using the host network.
+func handleListeningPorts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ListeningPortsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.Pid < 0 {
+		http.Error(w, "Invalid pid", http.StatusBadRequest)
+		return
+	}
+
+	procDir := "/proc/self"
+	var inodes map[string]bool
+	if req.Pid > 0 {
+		procDir = filepath.Join("/proc", strconv.Itoa(req.Pid))
+		var err error
+		if inodes, err = pidNamespaceSockets(procDir); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to list sockets of process %d: %v", req.Pid, err), http.StatusNotFound)
+			return
+		}
+	}
+
+	ports := make([]ListeningPort, 0)
+	seen := make(map[ListeningPort]bool)
+	for _, name := range []string{"tcp", "tcp6"} {
+		listeners, err := readListeningSockets(filepath.Join(procDir, "net", name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				// IPv6 may be disabled
+				continue
+			}
+			http.Error(w, fmt.Sprintf("Failed to read %s sockets: %v", name, err), http.StatusInternalServerError)
+			return
+		}
+		for inode, port := range listeners {
+			if inodes != nil && !inodes[inode] {
+				continue
+			}
+			if !seen[port] {
+				seen[port] = true
+				ports = append(ports, port)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ports)
+}
+
+// readListeningSockets parses a /proc/net/tcp or /proc/net/tcp6 file into the
+// listening sockets by inode
+func readListeningSockets(path string) (map[string]ListeningPort, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	listeners := make(map[string]ListeningPort)
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // Header
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != tcpListenState {
+			continue
+		}
+		hexAddress, hexPort, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			continue
+		}
+		ip, err := parseProcIP(hexAddress)
+		if err != nil {
+			continue
+		}
+		port, err := strconv.ParseUint(hexPort, 16, 16)
+		if err != nil {
+			continue
+		}
+		listeners[fields[9]] = ListeningPort{Address: ip.String(), Port: int(port)}
+	}
+	return listeners, scanner.Err()
+}
+
+// parseProcIP parses an address of /proc/net/tcp, stored as 32-bit words in
+// host byte order, which is little endian on the platforms Docker runs on
+func parseProcIP(s string) (net.IP, error) {
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != net.IPv4len && len(raw) != net.IPv6len {
+		return nil, fmt.Errorf("invalid address length %d", len(raw))
+	}
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+	return ip, nil
+}
+
+// pidNamespaceSockets returns the inodes of the sockets opened by the
+// processes in the PID namespace of the process at procDir
+func pidNamespaceSockets(procDir string) (map[string]bool, error) {
+	namespace, err := os.Readlink(filepath.Join(procDir, "ns", "pid"))
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	inodes := make(map[string]bool)
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		dir := filepath.Join("/proc", entry.Name())
+		if ns, err := os.Readlink(filepath.Join(dir, "ns", "pid")); err != nil || ns != namespace {
+			continue
+		}
+		fds, err := os.ReadDir(filepath.Join(dir, "fd"))
+		if err != nil {
+			// The process exited
+			continue
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(dir, "fd", fd.Name()))
+			if err != nil {
+				continue
+			}
+			// Socket links look like "socket:[12345]"
+			if inode, ok := strings.CutPrefix(target, "socket:["); ok {
+				inodes[strings.TrimSuffix(inode, "]")] = true
+			}
+		}
+	}
+	return inodes, nil
+}
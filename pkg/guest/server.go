@@ -114,6 +114,10 @@ func RunServerWithConfig(config *ServerConfig) {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/tinyscale/v1/host-exec/command", handleCommand)
 	mux.HandleFunc("/tinyscale/v1/host-exec/directories", handleCreateDirectories)
+	mux.HandleFunc("/tinyscale/v1/host-exec/extract", handleExtract)
+	mux.HandleFunc("/tinyscale/v1/host-exec/file", handleWriteFile)
+	mux.HandleFunc("/tinyscale/v1/host-exec/forward-udp", handleForwardUDP)
+	mux.HandleFunc("/tinyscale/v1/host-exec/listening-ports", handleListeningPorts)
 
 	addr := fmt.Sprintf(":%d", config.Port)
 	log.Printf("Starting guest agent on %s", addr)
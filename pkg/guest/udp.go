@@ -0,0 +1,113 @@
+package guest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/teamycloud/tsctl/pkg/utils"
+)
+
+// ForwardUDPRequest selects the local UDP port that datagrams are relayed to,
+// on the loopback address Host, 127.0.0.1 when empty
+type ForwardUDPRequest struct {
+	Host string `json:"host,omitempty"`
+	Port int    `json:"port"`
+}
+
+// handleForwardUDP upgrades the connection and relays length-prefixed
+// datagrams between the stream and a UDP port on the loopback interface,
+// where Docker publishes container ports
+func handleForwardUDP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Check for Upgrade header
+	if strings.ToLower(r.Header.Get("Upgrade")) != "tcp" {
+		http.Error(w, "Upgrade: tcp header required", http.StatusBadRequest)
+		return
+	}
+
+	var req ForwardUDPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.Port <= 0 || req.Port > 65535 {
+		http.Error(w, "Invalid port", http.StatusBadRequest)
+		return
+	}
+
+	if req.Host == "" {
+		req.Host = "127.0.0.1"
+	}
+	if ip := net.ParseIP(req.Host); ip == nil || !ip.IsLoopback() {
+		http.Error(w, "Invalid host, a loopback address is required", http.StatusBadRequest)
+		return
+	}
+
+	udpConn, err := net.Dial("udp", net.JoinHostPort(req.Host, strconv.Itoa(req.Port)))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to open UDP socket: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer udpConn.Close()
+
+	conn, reader, err := upgradeToTCP(w)
+	if err != nil {
+		log.Printf("Failed to upgrade connection: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// Register the connection
+	processRegistry.AddConnection(conn)
+	defer processRegistry.RemoveConnection(conn)
+
+	done := make(chan struct{}, 2)
+
+	// Stream to UDP
+	go func() {
+		defer func() { done <- struct{}{} }()
+		buf := make([]byte, utils.MaxDatagramSize)
+		for {
+			n, err := utils.ReadDatagram(reader, buf)
+			if err != nil {
+				return
+			}
+			if _, err := udpConn.Write(buf[:n]); err != nil {
+				log.Printf("Failed to send datagram to port %d: %v", req.Port, err)
+			}
+		}
+	}()
+
+	// UDP to stream
+	go func() {
+		defer func() { done <- struct{}{} }()
+		buf := make([]byte, utils.MaxDatagramSize)
+		for {
+			n, err := udpConn.Read(buf)
+			if err != nil {
+				// Nothing listening yet, the client may retry
+				if errors.Is(err, syscall.ECONNREFUSED) {
+					continue
+				}
+				return
+			}
+			if err := utils.WriteDatagram(conn, buf[:n]); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Closing both ends on return unblocks the other direction
+	<-done
+}
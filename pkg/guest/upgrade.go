@@ -0,0 +1,42 @@
+package guest
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// upgradeToTCP hijacks the connection of an `Upgrade: tcp` request and sends
+// the 101 Switching Protocols response. The returned reader holds any data
+// the client sent after the request.
+func upgradeToTCP(w http.ResponseWriter) (net.Conn, *bufio.Reader, error) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "Hijacking not supported", http.StatusInternalServerError)
+		return nil, nil, fmt.Errorf("hijacking not supported")
+	}
+
+	conn, bufrw, err := hijacker.Hijack()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Hijack failed: %v", err), http.StatusInternalServerError)
+		return nil, nil, fmt.Errorf("hijack failed: %w", err)
+	}
+
+	// Send 101 Switching Protocols response
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: tcp\r\n" +
+		"Connection: Upgrade\r\n" +
+		"\r\n"
+
+	if _, err := bufrw.WriteString(response); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to write upgrade response: %w", err)
+	}
+	if err := bufrw.Flush(); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to flush upgrade response: %w", err)
+	}
+
+	return conn, bufrw.Reader, nil
+}
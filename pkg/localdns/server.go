@@ -0,0 +1,152 @@
+package localdns
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// recordTTL is the TTL of answers in seconds, kept short since addresses
+	// are released when containers stop
+	recordTTL = 5
+	// maxMessageSize is the largest DNS message accepted over UDP
+	maxMessageSize = 1232
+)
+
+// Resolver maps a hostname to an IPv4 address
+type Resolver func(hostname string) (net.IP, bool)
+
+// Server is a minimal DNS server answering A queries for a single domain.
+// Names outside the domain are refused, so a system resolver configured to
+// send only that domain here keeps using its regular servers for the rest.
+type Server struct {
+	addr    string
+	domain  string
+	resolve Resolver
+	logger  *logging.Logger
+
+	mu     sync.Mutex
+	conn   net.PacketConn
+	closed bool
+}
+
+// NewServer creates a DNS server listening on addr (e.g., "127.0.0.1:15353")
+// and answering for names under domain
+func NewServer(addr string, domain string, resolve Resolver, logger *logging.Logger) *Server {
+	return &Server{
+		addr:    addr,
+		domain:  strings.ToLower(strings.TrimSuffix(domain, ".")),
+		resolve: resolve,
+		logger:  logger,
+	}
+}
+
+// ListenAndServe answers queries until Close is called
+func (s *Server) ListenAndServe() error {
+	conn, err := net.ListenPacket("udp", s.addr)
+	if err != nil {
+		return fmt.Errorf("unable to listen on %s: %w", s.addr, err)
+	}
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return conn.Close()
+	}
+	s.conn = conn
+	s.mu.Unlock()
+	s.logger.Infof("DNS server for *.%s listening on %s", s.domain, s.addr)
+
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		response, err := s.answer(buf[:n])
+		if err != nil {
+			s.logger.Debugf("Ignored invalid DNS query from %s: %v", addr, err)
+			continue
+		}
+		if _, err := conn.WriteTo(response, addr); err != nil {
+			s.logger.Debugf("Failed to send DNS response to %s: %v", addr, err)
+		}
+	}
+}
+
+// Close stops the server
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.Close()
+}
+
+// answer builds the response to a query
+func (s *Server) answer(query []byte) ([]byte, error) {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(query)
+	if err != nil {
+		return nil, err
+	}
+	question, err := parser.Question()
+	if err != nil {
+		return nil, err
+	}
+
+	response := dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:                 header.ID,
+			Response:           true,
+			Authoritative:      true,
+			RecursionDesired:   header.RecursionDesired,
+			RecursionAvailable: false,
+			RCode:              dnsmessage.RCodeSuccess,
+		},
+		Questions: []dnsmessage.Question{question},
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(question.Name.String(), "."))
+	switch {
+	case header.OpCode != 0 || question.Class != dnsmessage.ClassINET:
+		response.RCode = dnsmessage.RCodeNotImplemented
+	case name != s.domain && !strings.HasSuffix(name, "."+s.domain):
+		response.RCode = dnsmessage.RCodeRefused
+		response.Authoritative = false
+	default:
+		ip, ok := s.resolve(name)
+		if !ok {
+			response.RCode = dnsmessage.RCodeNameError
+			break
+		}
+		// Other query types get an empty answer, so clients fall back to A
+		if ipv4 := ip.To4(); ipv4 != nil && (question.Type == dnsmessage.TypeA || question.Type == dnsmessage.TypeALL) {
+			resource := dnsmessage.AResource{}
+			copy(resource.A[:], ipv4)
+			response.Answers = append(response.Answers, dnsmessage.Resource{
+				Header: dnsmessage.ResourceHeader{
+					Name:  question.Name,
+					Type:  dnsmessage.TypeA,
+					Class: dnsmessage.ClassINET,
+					TTL:   recordTTL,
+				},
+				Body: &resource,
+			})
+		}
+	}
+
+	return response.Pack()
+}
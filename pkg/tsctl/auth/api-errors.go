@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrUnauthorized is matched by API errors with status 401
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is matched by API errors with status 403
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotFound is matched by API errors with status 404
+	ErrNotFound = errors.New("not found")
+)
+
+// APIError is returned when the OpenAPI responds with a non-2xx status. Use
+// errors.Is with ErrUnauthorized, ErrForbidden or ErrNotFound to check for
+// the common cases.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s %s failed with status %d", e.Method, e.Path, e.StatusCode)
+	}
+	return fmt.Sprintf("%s %s failed with status %d: %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// Unwrap maps the status code to the matching sentinel error
+func (e *APIError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	default:
+		return nil
+	}
+}
@@ -1,61 +1,272 @@
 package auth
 
 import (
+	"bytes"
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/teamycloud/tsctl/pkg/tsctl/i18n"
+	"github.com/teamycloud/tsctl/pkg/version"
+)
+
+const (
+	// apiMaxRetries is the number of times a request is retried on 429 and 5xx
+	apiMaxRetries = 3
+	// apiInitialBackoff is the delay before the first retry, doubled on each attempt
+	apiInitialBackoff = 500 * time.Millisecond
+	// apiMaxBackoff caps the delay between retries, including Retry-After
+	apiMaxBackoff = 30 * time.Second
+	// apiMaxPages guards against pagination loops
+	apiMaxPages = 100
+	// apiMaxErrorMessage is the maximum length of a response body kept in an APIError
+	apiMaxErrorMessage = 512
 )
 
 // APIClient handles Tinyscale OpenAPI calls
 type APIClient struct {
 	httpClient  *http.Client
 	apiEndpoint string
-	idToken     string
+	authData    *AuthData
 }
 
-// NewAPIClient creates a new API client
-func NewAPIClient(apiEndpoint, idToken string) *APIClient {
+// NewAPIClient creates a new API client authenticated with the id_token of
+// authData. When the OpenAPI rejects the token, it is refreshed once with
+// the stored refresh token and the request is retried.
+func NewAPIClient(apiEndpoint string, authData *AuthData) *APIClient {
 	return &APIClient{
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 		apiEndpoint: apiEndpoint,
-		idToken:     idToken,
+		authData:    authData,
 	}
 }
 
 // GetMyOrganizations fetches the list of organizations for the current user
 func (c *APIClient) GetMyOrganizations() ([]Organization, error) {
-	endpoint := c.apiEndpoint + OrganizationsPath
+	orgs, err := listAll[Organization](c, OrganizationsPath)
+	if err != nil {
+		return nil, i18n.Errorf("auth.api.error.list-organizations", err)
+	}
+	return orgs, nil
+}
+
+// listAll fetches every page of a list endpoint. Pages are chained through
+// the `Link: <url>; rel="next"` response header.
+func listAll[T any](c *APIClient, path string) ([]T, error) {
+	var items []T
+	for page := 0; path != ""; page++ {
+		if page == apiMaxPages {
+			return nil, i18n.Errorf("auth.api.error.too-many-pages", path)
+		}
+
+		var pageItems []T
+		resp, err := c.do(http.MethodGet, path, nil, &pageItems)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, pageItems...)
+
+		path = nextPage(resp)
+		if path != "" && !c.sameOrigin(path) {
+			return nil, i18n.Errorf("auth.api.error.next-page", path, c.apiEndpoint)
+		}
+	}
+	return items, nil
+}
+
+// do sends a request to the OpenAPI, decoding the JSON response into out
+// when it is non-nil. Idempotent requests are retried on 429 and 5xx
+// responses with an exponential backoff, honoring Retry-After, and all
+// requests once after refreshing the id_token on 401.
+func (c *APIClient) do(method, path string, body, out any) (*http.Response, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, i18n.Errorf("auth.api.error.marshal", err)
+		}
+	}
+
+	refreshed := false
+	backoff := apiInitialBackoff
+	for attempt := 0; ; attempt++ {
+		resp, data, err := c.send(method, path, payload)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			if out != nil && len(data) > 0 {
+				if err := json.Unmarshal(data, out); err != nil {
+					return nil, i18n.Errorf("auth.api.error.parse-response", method, path, err)
+				}
+			}
+			return resp, nil
+
+		case resp.StatusCode == http.StatusUnauthorized && !refreshed && c.canRefresh():
+			refreshed = true
+			if err := RefreshAuthData(c.authData); err != nil {
+				return nil, i18n.Errorf("auth.error.refresh", err)
+			}
+			continue
 
-	req, err := http.NewRequest("GET", endpoint, nil)
+		case retryable(resp.StatusCode) && idempotent(method) && attempt < apiMaxRetries:
+			delay := backoff
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = retryAfter
+			}
+			time.Sleep(min(delay, apiMaxBackoff))
+			backoff *= 2
+			continue
+		}
+
+		return nil, &APIError{
+			Method:     method,
+			Path:       path,
+			StatusCode: resp.StatusCode,
+			Message:    errorMessage(data),
+		}
+	}
+}
+
+// send performs a single HTTP round trip and reads the whole response body
+func (c *APIClient) send(method, path string, payload []byte) (*http.Response, []byte, error) {
+	endpoint := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		endpoint = c.apiEndpoint + path
+	}
+
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, endpoint, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("unable to create organizations request: %w", err)
+		return nil, nil, i18n.Errorf("auth.api.error.request", err)
+	}
+	// The id_token is only ever sent to the OpenAPI itself
+	if c.authData != nil && c.authData.Token != nil && c.sameOrigin(endpoint) {
+		req.Header.Set("Authorization", "Bearer "+c.authData.Token.IDToken)
 	}
-	req.Header.Set("Authorization", "Bearer "+c.idToken)
 	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", version.UserAgent())
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("unable to send organizations request: %w", err)
+		return nil, nil, i18n.Errorf("auth.api.error.send", method, path, err)
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("unable to read organizations response: %w", err)
+		return nil, nil, i18n.Errorf("auth.api.error.read-response", method, path, err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("organizations request failed with status %d: %s", resp.StatusCode, string(body))
+	return resp, data, nil
+}
+
+// canRefresh checks if the client holds a refresh token
+func (c *APIClient) canRefresh() bool {
+	return c.authData != nil && c.authData.Token != nil && c.authData.Token.RefreshToken != ""
+}
+
+// sameOrigin checks if an absolute URL has the scheme and host of the
+// OpenAPI endpoint
+func (c *APIClient) sameOrigin(endpoint string) bool {
+	api, err := url.Parse(c.apiEndpoint)
+	if err != nil {
+		return false
 	}
+	target, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(target.Scheme, api.Scheme) && strings.EqualFold(target.Host, api.Host)
+}
 
-	var orgs []Organization
-	if err := json.Unmarshal(body, &orgs); err != nil {
-		return nil, fmt.Errorf("unable to parse organizations response: %w", err)
+// idempotent checks if a request may be sent again without side effects
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
 	}
+	return false
+}
 
-	return orgs, nil
+// retryable checks if a response status is worth retrying
+func retryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// parseRetryAfter parses a Retry-After header, given either in seconds or
+// as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// nextPage extracts the URL of the next page from the Link header of resp,
+// resolving relative links against the request URL
+func nextPage(resp *http.Response) string {
+	for _, link := range resp.Header.Values("Link") {
+		for _, part := range strings.Split(link, ",") {
+			segments := strings.Split(part, ";")
+			target := strings.TrimSpace(segments[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range segments[1:] {
+				if strings.ReplaceAll(strings.TrimSpace(param), " ", "") == `rel="next"` {
+					next, err := resp.Request.URL.Parse(target[1 : len(target)-1])
+					if err != nil {
+						return ""
+					}
+					return next.String()
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// errorMessage extracts a readable message from an error response body,
+// preferring the "message" or "error" field of a JSON body
+func errorMessage(data []byte) string {
+	var payload struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	if json.Unmarshal(data, &payload) == nil {
+		if payload.Message != "" {
+			return payload.Message
+		}
+		if payload.Error != "" {
+			return payload.Error
+		}
+	}
+
+	message := strings.TrimSpace(string(data))
+	if len(message) > apiMaxErrorMessage {
+		message = message[:apiMaxErrorMessage] + "..."
+	}
+	return message
 }
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teamycloud/tsctl/pkg/tsctl/i18n"
+)
+
+// errCredentialsNotFound is the message Docker expects from a credential
+// helper when it has no credentials for the requested server
+var errCredentialsNotFound = errors.New("credentials not found in native keychain")
+
+// dockerCredentials is the payload exchanged with Docker by the credential
+// helper protocol on stdin/stdout
+type dockerCredentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// NewDockerCredentialCommand creates the docker credential helper command.
+// Docker invokes the helper as `docker-credential-tinyscale <action>`, so
+// tsctl is usually symlinked under that name, but the helper can also be
+// run as `tsctl auth docker-credential <action>`.
+func NewDockerCredentialCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:       "docker-credential get|store|erase|list",
+		Short:     i18n.T("auth.docker-credential.short"),
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"get", "store", "erase", "list"},
+		Run: func(cmd *cobra.Command, args []string) {
+			ServeDockerCredentialHelper(args)
+		},
+		Hidden: true, // this command is invoked by docker
+	}
+
+	return cmd
+}
+
+// ServeDockerCredentialHelper runs the credential helper action named by the
+// first argument and exits. As required by the protocol, errors are written
+// to stdout and reported with a non-zero exit code.
+func ServeDockerCredentialHelper(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stdout, "Usage: %s get|store|erase|list\n", DockerCredentialHelperName)
+		os.Exit(1)
+	}
+
+	if err := RunDockerCredentialHelper(args[0], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stdout, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// RunDockerCredentialHelper runs one action of the Docker credential helper
+// protocol, reading its input from in and writing its output to out
+func RunDockerCredentialHelper(action string, in io.Reader, out io.Writer) error {
+	switch action {
+	case "get":
+		return credentialGet(in, out)
+	case "store":
+		return credentialStore(in)
+	case "erase":
+		return credentialErase(in)
+	case "list":
+		return credentialList(out)
+	default:
+		return i18n.Errorf("auth.docker-credential.error.action", action)
+	}
+}
+
+// credentialGet returns the id_token of the logged-in user for the Tinyscale registry
+func credentialGet(in io.Reader, out io.Writer) error {
+	serverURL, err := readServerURL(in)
+	if err != nil {
+		return err
+	}
+
+	// Never hand out the id_token to a registry other than ours, in case the
+	// helper has been configured as the global credsStore
+	if !isTinyscaleRegistry(serverURL) {
+		return errCredentialsNotFound
+	}
+
+	authData, err := LoadValidAuthData()
+	if err != nil {
+		return err
+	}
+	if authData == nil {
+		return errCredentialsNotFound
+	}
+
+	return json.NewEncoder(out).Encode(dockerCredentials{
+		ServerURL: serverURL,
+		Username:  RegistryUsername,
+		Secret:    authData.Token.IDToken,
+	})
+}
+
+// credentialStore rejects credentials from `docker login`, the registry
+// credentials always derive from `tsctl auth login`
+func credentialStore(in io.Reader) error {
+	var creds dockerCredentials
+	if err := json.NewDecoder(in).Decode(&creds); err != nil {
+		return i18n.Errorf("auth.docker-credential.error.parse", err)
+	}
+
+	if isTinyscaleRegistry(creds.ServerURL) {
+		return i18n.Errorf("auth.docker-credential.error.managed", creds.ServerURL)
+	}
+	return i18n.Errorf("auth.docker-credential.error.registry", GetRegistryEndpoint())
+}
+
+// credentialErase is a no-op, use `tsctl auth logout` to remove the stored tokens
+func credentialErase(in io.Reader) error {
+	_, err := readServerURL(in)
+	return err
+}
+
+// credentialList lists the registries the helper has credentials for
+func credentialList(out io.Writer) error {
+	registries := make(map[string]string)
+
+	authData, err := LoadAuthData()
+	if err != nil {
+		return err
+	}
+	if authData != nil && authData.Token != nil && authData.Token.IDToken != "" {
+		registries[GetRegistryEndpoint()] = RegistryUsername
+	}
+
+	return json.NewEncoder(out).Encode(registries)
+}
+
+// readServerURL reads the server URL Docker writes to stdin for get and erase
+func readServerURL(in io.Reader) (string, error) {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return "", i18n.Errorf("auth.docker-credential.error.url", err)
+	}
+
+	serverURL := strings.TrimSpace(string(data))
+	if serverURL == "" {
+		return "", i18n.Errorf("auth.docker-credential.error.no-url")
+	}
+	return serverURL, nil
+}
+
+// isTinyscaleRegistry checks if the server URL points to the configured registry
+func isTinyscaleRegistry(serverURL string) bool {
+	return registryHost(serverURL) == registryHost(GetRegistryEndpoint())
+}
+
+// registryHost extracts the lower-cased host[:port] from a registry address
+// such as "https://registry.tinyscale.com/v2/" or "registry.tinyscale.com"
+func registryHost(address string) string {
+	if i := strings.Index(address, "://"); i >= 0 {
+		address = address[i+3:]
+	}
+	if i := strings.Index(address, "/"); i >= 0 {
+		address = address[:i]
+	}
+	return strings.ToLower(address)
+}
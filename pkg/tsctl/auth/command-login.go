@@ -4,21 +4,16 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/teamycloud/tsctl/pkg/tsctl/i18n"
 )
 
 // NewLoginCommand creates the login command
 func NewLoginCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "login",
-		Short: "登录到 Tinyscale",
-		Long: `使用 OAuth2 设备代码流登录到 Tinyscale。
-
-此命令将：
-1. 显示一个验证 URL 和代码
-2. 等待你在浏览器中完成身份验证
-3. 将临时凭据保存到本地
-4. 提示你选择一个活跃的组织`,
-		RunE: runLogin,
+		Short: i18n.T("auth.login.short"),
+		Long:  i18n.T("auth.login.long"),
+		RunE:  runLogin,
 	}
 
 	return cmd
@@ -28,38 +23,39 @@ func runLogin(cmd *cobra.Command, args []string) error {
 	authEndpoint := GetLoginEndpoint()
 	openAPIEndpoint := GetOpenAPIEndpoint()
 
-	fmt.Printf("Logging in to Tinyscale...\n")
-	fmt.Printf("Auth server: %s\n\n", authEndpoint)
+	fmt.Println(i18n.T("auth.login.start"))
+	fmt.Println(i18n.T("auth.login.server", authEndpoint))
+	fmt.Println()
 
 	// Step 1: Start device authorization
 	oauthClient := NewOAuthClient(authEndpoint)
 	deviceAuth, err := oauthClient.StartDeviceAuthorization()
 	if err != nil {
-		return fmt.Errorf("failed to start device authorization: %w", err)
+		return i18n.Errorf("auth.login.error.device-authorization", err)
 	}
 
 	// Step 2: Display verification info to user
-	fmt.Printf("要登录，请使用网页浏览器打开以下页面：\n")
+	fmt.Println(i18n.T("auth.login.open-page"))
 	fmt.Printf("  %s\n\n", deviceAuth.VerificationURI)
-	fmt.Printf("并输入代码：\n")
+	fmt.Println(i18n.T("auth.login.enter-code"))
 	fmt.Printf("  %s\n\n", deviceAuth.UserCode)
 
 	if deviceAuth.VerificationURIComplete != "" {
-		fmt.Printf("或者直接打开此 URL：\n")
+		fmt.Println(i18n.T("auth.login.open-url"))
 		fmt.Printf("  %s\n\n", deviceAuth.VerificationURIComplete)
 	}
 
-	fmt.Printf("等待身份验证完成...\n")
+	fmt.Println(i18n.T("auth.login.waiting"))
 	// Step 3: Poll for token
 	tokenResp, err := oauthClient.PollForToken(deviceAuth)
 	if err != nil {
-		return fmt.Errorf("无法完成登录: %w", err)
+		return i18n.Errorf("auth.login.error.poll", err)
 	}
 
 	// Step 4: Parse user info from id_token
 	userInfo, err := ExtractUserInfo(tokenResp.IDToken)
 	if err != nil {
-		return fmt.Errorf("无法解析用户信息: %w", err)
+		return i18n.Errorf("auth.login.error.user-info", err)
 	}
 
 	// Step 5: Save auth data (without organization for now)
@@ -77,10 +73,11 @@ func runLogin(cmd *cobra.Command, args []string) error {
 	}
 
 	if err := SaveAuthData(authData); err != nil {
-		return fmt.Errorf("无法保存临时凭据数据: %w", err)
+		return i18n.Errorf("auth.login.error.save", err)
 	}
 
-	fmt.Printf("欢迎回来，%s %s!\n\n", userInfo.FirstName, userInfo.LastName)
+	fmt.Println(i18n.T("auth.login.welcome", userInfo.FirstName, userInfo.LastName))
+	fmt.Println()
 
 	// Step 6: Trigger organization selection
 	return selectOrganization(authData)
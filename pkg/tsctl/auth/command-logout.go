@@ -4,17 +4,16 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/teamycloud/tsctl/pkg/tsctl/i18n"
 )
 
 // NewLogoutCommand creates the logout command
 func NewLogoutCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "logout",
-		Short: "注销登录 Tinyscale",
-		Long: `注销登录 Tinyscale 并清除本地凭据。
-
-此命令将删除本地存储的身份验证数据。`,
-		RunE: runLogout,
+		Short: i18n.T("auth.logout.short"),
+		Long:  i18n.T("auth.logout.long"),
+		RunE:  runLogout,
 	}
 
 	return cmd
@@ -23,16 +22,16 @@ func NewLogoutCommand() *cobra.Command {
 func runLogout(cmd *cobra.Command, args []string) error {
 	authData, err := LoadAuthData()
 	if err != nil {
-		return fmt.Errorf("无法加载身份验证数据: %w", err)
+		return i18n.Errorf("auth.error.load", err)
 	}
 
 	if authData == nil {
-		fmt.Println("你尚未登录。")
+		fmt.Println(i18n.T("auth.logout.not-logged-in"))
 		return nil
 	}
 
 	if err := ClearAuthData(); err != nil {
-		return fmt.Errorf("无法清除身份验证数据: %w", err)
+		return i18n.Errorf("auth.logout.error.clear", err)
 	}
 
 	userName := ""
@@ -41,9 +40,9 @@ func runLogout(cmd *cobra.Command, args []string) error {
 	}
 
 	if userName != "" {
-		fmt.Printf("%s 已成功注销登录。\n", userName)
+		fmt.Println(i18n.T("auth.logout.done-user", userName))
 	} else {
-		fmt.Println("成功注销登录。")
+		fmt.Println(i18n.T("auth.logout.done"))
 	}
 
 	return nil
@@ -2,19 +2,21 @@ package auth
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/teamycloud/tsctl/pkg/tsctl/i18n"
 )
 
 // NewAuthCommand creates the auth parent command
 func NewAuthCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "auth",
-		Short: "身份验证",
-		Long:  `管理 Tinyscale 身份验证和组织的命令。`,
+		Short: i18n.T("auth.short"),
+		Long:  i18n.T("auth.long"),
 	}
 
 	cmd.AddCommand(NewLoginCommand())
 	cmd.AddCommand(NewLogoutCommand())
 	cmd.AddCommand(NewSwitchOrgCommand())
+	cmd.AddCommand(NewDockerCredentialCommand())
 
 	return cmd
 }
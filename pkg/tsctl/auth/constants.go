@@ -12,6 +12,9 @@ var (
 
 	// DefaultConnectEndpoint is the default Tinyscale Connect endpoint
 	DefaultConnectEndpoint = "https://connect.tinyscale.com"
+
+	// DefaultRegistryEndpoint is the default Tinyscale container registry
+	DefaultRegistryEndpoint = "registry.tinyscale.com"
 )
 
 const (
@@ -27,6 +30,17 @@ const (
 	// EnvOpenAPIEndpoint is the environment variable for OpenAPI endpoint
 	EnvOpenAPIEndpoint = "TINYSCALE_ENDPOINT_OPENAPI"
 
+	// EnvRegistryEndpoint is the environment variable for the container registry endpoint
+	EnvRegistryEndpoint = "TINYSCALE_ENDPOINT_REGISTRY"
+
+	// DockerCredentialHelperName is the executable name Docker looks up for
+	// `"credHelpers": {"<registry>": "tinyscale"}`
+	DockerCredentialHelperName = "docker-credential-tinyscale"
+
+	// RegistryUsername is the username reported to Docker, the registry
+	// authenticates the id_token passed as the password
+	RegistryUsername = "oauth2"
+
 	// DeviceAuthorizationPath is the OAuth2 device authorization endpoint path
 	DeviceAuthorizationPath = "/device_authorization"
 
@@ -52,3 +66,11 @@ func GetOpenAPIEndpoint() string {
 	}
 	return DefaultOpenAPIEndpoint
 }
+
+// GetRegistryEndpoint returns the container registry endpoint from env or default
+func GetRegistryEndpoint() string {
+	if endpoint := os.Getenv(EnvRegistryEndpoint); endpoint != "" {
+		return endpoint
+	}
+	return DefaultRegistryEndpoint
+}
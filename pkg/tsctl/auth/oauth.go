@@ -2,8 +2,8 @@ package auth
 
 import (
 	"context"
-	"fmt"
 
+	"github.com/teamycloud/tsctl/pkg/tsctl/i18n"
 	"golang.org/x/oauth2"
 )
 
@@ -37,7 +37,7 @@ func (c *OAuthClient) StartDeviceAuthorization() (*oauth2.DeviceAuthResponse, er
 
 	deviceAuth, err := c.config.DeviceAuth(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("unable to start device authorization: %w", err)
+		return nil, i18n.Errorf("auth.oauth.error.device-authorization", err)
 	}
 
 	return deviceAuth, nil
@@ -49,13 +49,13 @@ func (c *OAuthClient) PollForToken(deviceAuth *oauth2.DeviceAuthResponse) (*Toke
 
 	token, err := c.config.DeviceAccessToken(ctx, deviceAuth)
 	if err != nil {
-		return nil, fmt.Errorf("failed to obtain token: %w", err)
+		return nil, i18n.Errorf("auth.oauth.error.token", err)
 	}
 
 	// Extract id_token from the extra fields
 	idToken, ok := token.Extra("id_token").(string)
 	if !ok || idToken == "" {
-		return nil, fmt.Errorf("id_token not found in token response - check OAuth provider configuration")
+		return nil, i18n.Errorf("auth.oauth.error.no-id-token")
 	}
 
 	return &TokenResponse{
@@ -78,13 +78,13 @@ func (c *OAuthClient) RefreshToken(refreshToken string) (*TokenResponse, error)
 	tokenSource := c.config.TokenSource(ctx, token)
 	newToken, err := tokenSource.Token()
 	if err != nil {
-		return nil, fmt.Errorf("unable to refresh token: %w", err)
+		return nil, i18n.Errorf("auth.error.refresh", err)
 	}
 
 	// Extract id_token from the extra fields
 	idToken, ok := newToken.Extra("id_token").(string)
 	if !ok || idToken == "" {
-		return nil, fmt.Errorf("id_token not found in refresh response - check OAuth provider configuration")
+		return nil, i18n.Errorf("auth.oauth.error.refresh-id-token")
 	}
 
 	return &TokenResponse{
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"github.com/teamycloud/tsctl/pkg/tsctl/i18n"
+)
+
+// LoadValidAuthData loads the stored authentication data and makes sure the
+// id_token is usable, refreshing it synchronously when it has expired or is
+// close to expiring. It returns nil if the user is not logged in.
+func LoadValidAuthData() (*AuthData, error) {
+	authData, err := LoadAuthData()
+	if err != nil {
+		return nil, err
+	}
+	if authData == nil || authData.Token == nil || authData.Token.IDToken == "" {
+		return nil, nil
+	}
+
+	expired, err := IsTokenExpired(authData.Token.IDToken)
+	if err != nil {
+		return nil, i18n.Errorf("auth.error.check-expiration", err)
+	}
+
+	shouldRefresh := expired
+	if !shouldRefresh {
+		// A failure here is not fatal, the current token is still valid
+		shouldRefresh, _ = ShouldRefreshToken(authData.Token.IDToken)
+	}
+
+	if shouldRefresh {
+		if err := RefreshAuthData(authData); err != nil {
+			if expired {
+				return nil, err
+			}
+			// The current token has not expired yet, keep using it
+		}
+	}
+
+	return authData, nil
+}
+
+// RefreshAuthData exchanges the stored refresh token for a new id_token and
+// saves the updated tokens to disk. authData is updated with the stored
+// authentication data, which holds the tokens of another process instead
+// when it refreshed them first.
+func RefreshAuthData(authData *AuthData) error {
+	if authData.Token == nil || authData.Token.RefreshToken == "" {
+		return i18n.Errorf("auth.error.no-refresh-token")
+	}
+
+	authEndpoint := GetLoginEndpoint()
+	if authData.Endpoints != nil && authData.Endpoints.Auth != "" {
+		authEndpoint = authData.Endpoints.Auth
+	}
+
+	refreshed, err := refreshStoredTokens(authEndpoint, authData.Token.RefreshToken)
+	if err != nil {
+		return err
+	}
+	*authData = *refreshed
+	return nil
+}
+
+// refreshStoredTokens exchanges a refresh token for new tokens and saves them
+// only if the stored refresh token still matches. Docker runs credential
+// helpers in parallel and the server rotates refresh tokens, overwriting the
+// tokens refreshed by another process would log the user out. It returns the
+// stored authentication data once refreshed, by this process or another one.
+func refreshStoredTokens(authEndpoint, refreshToken string) (*AuthData, error) {
+	oauthClient := NewOAuthClient(authEndpoint)
+	tokenResp, refreshErr := oauthClient.RefreshToken(refreshToken)
+
+	// Reload auth data from disk to get current state
+	authData, err := LoadAuthData()
+	if err != nil {
+		return nil, err
+	}
+	if authData == nil || authData.Token == nil {
+		return nil, i18n.Errorf("auth.error.not-logged-in")
+	}
+
+	// Another process refreshed the tokens meanwhile, which may also be why
+	// the server rejected the rotated refresh token
+	if authData.Token.RefreshToken != refreshToken {
+		return authData, nil
+	}
+	if refreshErr != nil {
+		return nil, refreshErr
+	}
+
+	authData.Token.IDToken = tokenResp.IDToken
+	if tokenResp.RefreshToken != "" {
+		authData.Token.RefreshToken = tokenResp.RefreshToken
+	}
+
+	// Update user info if changed
+	if userInfo, err := ExtractUserInfo(tokenResp.IDToken); err == nil {
+		authData.User = userInfo
+	}
+
+	if err := SaveAuthData(authData); err != nil {
+		return nil, i18n.Errorf("auth.error.save-refreshed", err)
+	}
+
+	return authData, nil
+}
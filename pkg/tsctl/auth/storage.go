@@ -2,9 +2,10 @@ package auth
 
 import (
 	"encoding/json"
-	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/teamycloud/tsctl/pkg/tsctl/i18n"
 )
 
 const (
@@ -18,7 +19,7 @@ const (
 func GetAuthFilePath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return "", fmt.Errorf("unable to get user home directory: %w", err)
+		return "", i18n.Errorf("auth.storage.error.home", err)
 	}
 	return filepath.Join(homeDir, TinyscaleDir, AuthFileName), nil
 }
@@ -35,12 +36,12 @@ func LoadAuthData() (*AuthData, error) {
 		if os.IsNotExist(err) {
 			return nil, nil // No auth data exists yet
 		}
-		return nil, fmt.Errorf("unable to read auth file: %w", err)
+		return nil, i18n.Errorf("auth.storage.error.read", err)
 	}
 
 	var authData AuthData
 	if err := json.Unmarshal(data, &authData); err != nil {
-		return nil, fmt.Errorf("unable to parse auth file: %w", err)
+		return nil, i18n.Errorf("auth.storage.error.parse", err)
 	}
 
 	return &authData, nil
@@ -56,16 +57,16 @@ func SaveAuthData(authData *AuthData) error {
 	// Ensure the directory exists
 	dir := filepath.Dir(authPath)
 	if err := os.MkdirAll(dir, 0700); err != nil {
-		return fmt.Errorf("unable to create auth directory: %w", err)
+		return i18n.Errorf("auth.storage.error.mkdir", err)
 	}
 
 	data, err := json.MarshalIndent(authData, "", "  ")
 	if err != nil {
-		return fmt.Errorf("unable to marshal auth data: %w", err)
+		return i18n.Errorf("auth.storage.error.marshal", err)
 	}
 
 	if err := os.WriteFile(authPath, data, 0600); err != nil {
-		return fmt.Errorf("unable to write auth file: %w", err)
+		return i18n.Errorf("auth.storage.error.write", err)
 	}
 
 	return nil
@@ -82,7 +83,7 @@ func ClearAuthData() error {
 		if os.IsNotExist(err) {
 			return nil // File doesn't exist, nothing to clear
 		}
-		return fmt.Errorf("unable to remove auth file: %w", err)
+		return i18n.Errorf("auth.storage.error.remove", err)
 	}
 
 	return nil
@@ -3,9 +3,10 @@ package auth
 import (
 	"encoding/base64"
 	"encoding/json"
-	"fmt"
 	"strings"
 	"time"
+
+	"github.com/teamycloud/tsctl/pkg/tsctl/i18n"
 )
 
 // ParseIDToken parses a JWT id_token and extracts the claims.
@@ -15,18 +16,18 @@ import (
 func ParseIDToken(idToken string) (*IDTokenClaims, error) {
 	parts := strings.Split(idToken, ".")
 	if len(parts) != 3 {
-		return nil, fmt.Errorf("invalid JWT format: expected 3 parts, got %d", len(parts))
+		return nil, i18n.Errorf("auth.token.error.format", len(parts))
 	}
 
 	// Decode the payload (second part)
 	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
 	if err != nil {
-		return nil, fmt.Errorf("unable to decode JWT payload: %w", err)
+		return nil, i18n.Errorf("auth.token.error.decode", err)
 	}
 
 	var claims IDTokenClaims
 	if err := json.Unmarshal(payload, &claims); err != nil {
-		return nil, fmt.Errorf("unable to parse JWT claims: %w", err)
+		return nil, i18n.Errorf("auth.token.error.claims", err)
 	}
 
 	return &claims, nil
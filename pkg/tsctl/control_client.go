@@ -0,0 +1,75 @@
+package tsctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/teamycloud/tsctl/pkg/daemon"
+	"github.com/teamycloud/tsctl/pkg/tsctl/i18n"
+	"github.com/teamycloud/tsctl/pkg/version"
+)
+
+// controlRequest sends a request to the control API of the running daemon,
+// decoding the JSON response into out when it is non-nil
+func controlRequest(method, path string, body, out any) error {
+	endpointPath, err := daemon.EndpointPath()
+	if err != nil {
+		return i18n.Errorf("control.error.endpoint-path", err)
+	}
+
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", endpointPath)
+			},
+		},
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return i18n.Errorf("control.error.marshal", err)
+		}
+		reqBody = bytes.NewReader(payload)
+	}
+
+	// The host is ignored, requests always go to the control socket
+	req, err := http.NewRequest(method, "http://tsctl"+path, reqBody)
+	if err != nil {
+		return i18n.Errorf("control.error.request", err)
+	}
+	req.Header.Set("User-Agent", version.UserAgent())
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return i18n.Errorf("control.error.not-running", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return i18n.Errorf("control.error.read", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return i18n.Errorf("control.error.status", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return i18n.Errorf("control.error.parse", err)
+		}
+	}
+	return nil
+}
@@ -2,17 +2,20 @@ package tsctl
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/teamycloud/tsctl/pkg/tsctl/i18n"
 )
 
 func NewDaemonCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "daemon",
-		Short: "Manage the Tinyscale proxy daemon",
-		Long:  `Commands for starting and stopping the Tinyscale local TCP proxy daemon`,
+		Short: i18n.T("daemon.short"),
+		Long:  i18n.T("daemon.long"),
 	}
 
 	cmd.AddCommand(NewStartCommand())
 	cmd.AddCommand(NewStopCommand())
+	cmd.AddCommand(NewStatusCommand())
+	cmd.AddCommand(NewSessionsCommand())
 
 	return cmd
 }
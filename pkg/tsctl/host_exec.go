@@ -14,8 +14,10 @@ import (
 
 	"github.com/spf13/cobra"
 	ts_tunnel "github.com/teamycloud/tsctl/pkg/ts-tunnel"
+	"github.com/teamycloud/tsctl/pkg/tsctl/i18n"
 	"github.com/teamycloud/tsctl/pkg/utils"
 	"github.com/teamycloud/tsctl/pkg/utils/tlsconfig"
+	"github.com/teamycloud/tsctl/pkg/version"
 )
 
 type CommandRequest struct {
@@ -36,19 +38,13 @@ func NewHostExecCommand() *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:   "host-exec [flags] -- COMMAND [args...]",
-		Short: "Execute a command on the container host",
-		Long: `Execute a command on the container host server provided by tinyscale.
-
-Use -- to separate ts flags from the command to execute and its arguments.
-
-Example:
-  tsctl host-exec --server=host:port -- ls -la
-  tsctl host-exec --server=host:port --insecure -- bash -c "echo hello"`,
+		Short: i18n.T("host-exec.short"),
+		Long:  i18n.T("host-exec.long"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// At this point, args contains everything after "--"
 			// Cobra automatically handles the "--" delimiter
 			if len(args) == 0 {
-				return fmt.Errorf("no command specified. Usage: tsctl host-exec [flags] -- COMMAND [args...]")
+				return i18n.Errorf("host-exec.error.no-command")
 			}
 
 			var tlsClientConfig *tls.Config
@@ -66,7 +62,7 @@ Example:
 
 				tlsClientConfig, err = cfgBuilder.Build()
 				if err != nil {
-					return fmt.Errorf("unable to build TLS configuration: %w", err)
+					return i18n.Errorf("host-exec.error.tls", err)
 				}
 			}
 
@@ -76,12 +72,12 @@ Example:
 	}
 
 	// Add flags
-	cmd.Flags().StringVar(&serverAddr, "server-addr", "", "Server address (ip:port or hostname:port)")
-	cmd.Flags().StringVar(&clientCertFile, "cert", "", "Client certificate file")
-	cmd.Flags().StringVar(&clientKeyFile, "key", "", "Client key file")
-	cmd.Flags().StringVar(&caCertFile, "ca", "", "CA certificate file")
-	cmd.Flags().BoolVar(&insecure, "insecure", false, "Skip TLS verification")
-	cmd.Flags().StringArrayVarP(&envs, "env", "e", []string{}, "Environment variable (can be repeated, format: KEY=VALUE)")
+	cmd.Flags().StringVar(&serverAddr, "server-addr", "", i18n.T("host-exec.flag.server-addr"))
+	cmd.Flags().StringVar(&clientCertFile, "cert", "", i18n.T("host-exec.flag.cert"))
+	cmd.Flags().StringVar(&clientKeyFile, "key", "", i18n.T("host-exec.flag.key"))
+	cmd.Flags().StringVar(&caCertFile, "ca", "", i18n.T("host-exec.flag.ca"))
+	cmd.Flags().BoolVar(&insecure, "insecure", false, i18n.T("host-exec.flag.insecure"))
+	cmd.Flags().StringArrayVarP(&envs, "env", "e", []string{}, i18n.T("host-exec.flag.env"))
 
 	cmd.MarkFlagRequired("server")
 
@@ -98,7 +94,7 @@ func executeCommand(serverAddr, command string, args []string, envs []string, tl
 	// Marshal to JSON
 	jsonData, err := json.Marshal(cmdReq)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to marshal command request: %v\n", err)
+		fmt.Fprintln(os.Stderr, i18n.T("host-exec.error.marshal", err))
 		os.Exit(-1)
 	}
 
@@ -109,14 +105,14 @@ func executeCommand(serverAddr, command string, args []string, envs []string, tl
 	url := fmt.Sprintf("%s://%s/tinyscale/v1/host-exec/command", scheme, serverAddr)
 	req, err := http.NewRequest("POST", url, bytes.NewReader(jsonData))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create request: %v\n", err)
+		fmt.Fprintln(os.Stderr, i18n.T("host-exec.error.request", err))
 		os.Exit(-1)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Upgrade", "tcp")
 	req.Header.Set("Connection", "Upgrade")
-	req.Header.Set("User-Agent", "tsctl")
+	req.Header.Set("User-Agent", version.UserAgent())
 
 	// Create TLS config if needed
 	var netConn net.Conn
@@ -124,7 +120,7 @@ func executeCommand(serverAddr, command string, args []string, envs []string, tl
 		// Manually dial with TLS
 		conn, err := tls.Dial("tcp", serverAddr, tlsCfg)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to connect to server: %v\n", err)
+			fmt.Fprintln(os.Stderr, i18n.T("host-exec.error.connect", err))
 			os.Exit(-1)
 		}
 		netConn = conn
@@ -132,7 +128,7 @@ func executeCommand(serverAddr, command string, args []string, envs []string, tl
 		// Dial without TLS
 		conn, err := net.Dial("tcp", serverAddr)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to connect to server: %v\n", err)
+			fmt.Fprintln(os.Stderr, i18n.T("host-exec.error.connect", err))
 			os.Exit(-1)
 		}
 		netConn = conn
@@ -141,7 +137,7 @@ func executeCommand(serverAddr, command string, args []string, envs []string, tl
 
 	// Write the HTTP request
 	if err := req.Write(netConn); err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to write request: %v\n", err)
+		fmt.Fprintln(os.Stderr, i18n.T("host-exec.error.write", err))
 		os.Exit(-1)
 	}
 
@@ -149,19 +145,19 @@ func executeCommand(serverAddr, command string, args []string, envs []string, tl
 	reader := bufio.NewReader(netConn)
 	resp, err := http.ReadResponse(reader, req)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to read response: %v\n", err)
+		fmt.Fprintln(os.Stderr, i18n.T("host-exec.error.read", err))
 		os.Exit(-1)
 	}
 
 	// Check if connection was upgraded
 	if resp.StatusCode != http.StatusSwitchingProtocols {
 		body, _ := io.ReadAll(resp.Body)
-		fmt.Fprintf(os.Stderr, "Failed to upgrade connection: %s - %s\n", resp.Status, string(body))
+		fmt.Fprintln(os.Stderr, i18n.T("host-exec.error.upgrade", resp.Status, string(body)))
 		os.Exit(-1)
 	}
 
 	if strings.ToLower(resp.Header.Get("Upgrade")) != "tcp" {
-		fmt.Fprintf(os.Stderr, "Server did not upgrade to TCP\n")
+		fmt.Fprintln(os.Stderr, i18n.T("host-exec.error.not-upgraded"))
 		os.Exit(-1)
 	}
 
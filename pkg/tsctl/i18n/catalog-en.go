@@ -0,0 +1,200 @@
+package i18n
+
+// catalogEN is the English message catalog
+var catalogEN = map[string]string{
+	"root.short":     "tinyscale - your container runtime on the cloud",
+	"root.long":      "Utilities for managing and connecting container hosts on the tinyscale platform",
+	"root.flag.lang": "Language of the CLI messages (en, zh-CN)",
+
+	// auth
+	"auth.short":                            "Authentication",
+	"auth.long":                             "Commands for managing Tinyscale authentication and organizations.",
+	"auth.error.load":                       "unable to load authentication data: %w",
+	"auth.error.not-logged-in":              "please log in first with 'tsctl auth login'",
+	"auth.error.check-expiration":           "unable to check whether the token has expired: %w",
+	"auth.error.session-expired":            "your session has expired, please log in again with 'tsctl auth login'",
+	"auth.error.refresh":                    "unable to refresh token: %w",
+	"auth.error.no-refresh-token":           "no refresh token available",
+	"auth.error.save-refreshed":             "unable to save refreshed tokens: %w",
+	"auth.storage.error.home":               "unable to get user home directory: %w",
+	"auth.storage.error.read":               "unable to read auth file: %w",
+	"auth.storage.error.parse":              "unable to parse auth file: %w",
+	"auth.storage.error.mkdir":              "unable to create auth directory: %w",
+	"auth.storage.error.marshal":            "unable to marshal auth data: %w",
+	"auth.storage.error.write":              "unable to write auth file: %w",
+	"auth.storage.error.remove":             "unable to remove auth file: %w",
+	"auth.api.error.list-organizations":     "unable to list organizations: %w",
+	"auth.api.error.too-many-pages":         "too many pages returned by %s",
+	"auth.api.error.next-page":              "refusing next page %s outside of %s",
+	"auth.api.error.marshal":                "unable to marshal request body: %w",
+	"auth.api.error.request":                "unable to create request: %w",
+	"auth.api.error.send":                   "unable to send %s %s: %w",
+	"auth.api.error.read-response":          "unable to read response of %s %s: %w",
+	"auth.api.error.parse-response":         "unable to parse response of %s %s: %w",
+	"auth.oauth.error.device-authorization": "unable to start device authorization: %w",
+	"auth.oauth.error.token":                "failed to obtain token: %w",
+	"auth.oauth.error.no-id-token":          "id_token not found in token response - check OAuth provider configuration",
+	"auth.oauth.error.refresh-id-token":     "id_token not found in refresh response - check OAuth provider configuration",
+	"auth.token.error.format":               "invalid JWT format: expected 3 parts, got %d",
+	"auth.token.error.decode":               "unable to decode JWT payload: %w",
+	"auth.token.error.claims":               "unable to parse JWT claims: %w",
+	"auth.warning.token-status":             "Warning: unable to check token status: %v",
+	"auth.login.short":                      "Log in to Tinyscale",
+	"auth.login.long":                       "Log in to Tinyscale using the OAuth2 device code flow.\n\nThis command will:\n1. Display a verification URL and code\n2. Wait for you to complete authentication in your browser\n3. Save the temporary credentials locally\n4. Prompt you to select an active organization",
+	"auth.login.start":                      "Logging in to Tinyscale...",
+	"auth.login.server":                     "Auth server: %s",
+	"auth.login.open-page":                  "To log in, open the following page in a web browser:",
+	"auth.login.enter-code":                 "and enter the code:",
+	"auth.login.open-url":                   "Or open this URL directly:",
+	"auth.login.waiting":                    "Waiting for authentication to complete...",
+	"auth.login.welcome":                    "Welcome back, %s %s!",
+	"auth.login.error.device-authorization": "failed to start device authorization: %w",
+	"auth.login.error.poll":                 "unable to complete login: %w",
+	"auth.login.error.user-info":            "unable to parse user information: %w",
+	"auth.login.error.save":                 "unable to save temporary credentials: %w",
+	"auth.logout.short":                     "Log out of Tinyscale",
+	"auth.logout.long":                      "Log out of Tinyscale and clear the local credentials.\n\nThis command deletes the locally stored authentication data.",
+	"auth.logout.not-logged-in":             "You are not logged in.",
+	"auth.logout.done-user":                 "%s has been logged out.",
+	"auth.logout.done":                      "Logged out.",
+	"auth.logout.error.clear":               "unable to clear authentication data: %w",
+	"auth.switch-org.short":                 "Switch the active organization",
+	"auth.switch-org.long":                  "Switch the active organization for Tinyscale operations.\n\nThis command will:\n1. Fetch the organizations you belong to\n2. Prompt you to select one as the active organization\n3. Save your choice locally for subsequent commands",
+	"auth.switch-org.no-organization":       "You have not joined any organization.",
+	"auth.switch-org.select":                "Please select an organization:",
+	"auth.switch-org.current":               " (current)",
+	"auth.switch-org.prompt":                "Enter a number (1-%d): ",
+	"auth.switch-org.invalid-number":        "Please enter a number between 1 and %d",
+	"auth.switch-org.done":                  "Active organization set to: %s",
+	"auth.switch-org.error.list":            "unable to fetch organizations: %w",
+	"auth.switch-org.error.save":            "unable to save organization selection: %w",
+	"auth.switch-org.error.input":           "unable to read input: %w",
+	"auth.docker-credential.short":          "Docker credential helper backed by the Tinyscale login",
+	"auth.docker-credential.error.action":   "unknown credential action: %s",
+	"auth.docker-credential.error.managed":  "credentials for %s are managed by 'tsctl auth login'",
+	"auth.docker-credential.error.registry": "the tinyscale credential helper only serves %s",
+	"auth.docker-credential.error.parse":    "unable to parse credentials: %w",
+	"auth.docker-credential.error.url":      "unable to read server URL: %w",
+	"auth.docker-credential.error.no-url":   "no credentials server URL",
+
+	// daemon
+	"daemon.short":                               "Manage the Tinyscale proxy daemon",
+	"daemon.long":                                "Commands for starting and stopping the Tinyscale local TCP proxy daemon",
+	"daemon.flag.log-level":                      "Log level",
+	"daemon.warning.log-level":                   "WARNING: invalid log level specified in environment: %s, default log level 'info' will be used",
+	"daemon.error.terminate-path":                "unable to compute terminate file path: %w",
+	"daemon.start.short":                         "Start the local proxy for Tinyscale Container API",
+	"daemon.start.long":                          "Start the TCP proxy server that forwards Container API calls to a remote daemon over running Tinyscale",
+	"daemon.start.banner":                        "\nStarting TCP proxy with %s transport...\n  Listen: %s\n  Remote: %s\n",
+	"daemon.start.started":                       "Proxy started. Press Ctrl+C to stop.",
+	"daemon.start.docker-host":                   "Use: export DOCKER_HOST=tcp://%s",
+	"daemon.start.flag.allow-public-ports":       "Honor non-loopback HostIp in port bindings, e.g. 0.0.0.0 to share a port on the LAN",
+	"daemon.start.flag.listen":                   "Local address to listen on",
+	"daemon.start.flag.ssh-user":                 "SSH username",
+	"daemon.start.flag.ssh-host":                 "SSH host and port",
+	"daemon.start.flag.ssh-key":                  "Path to SSH private key",
+	"daemon.start.flag.guest-addr":               "Address of the guest agent on the remote host when using the SSH transport",
+	"daemon.start.flag.remote-docker":            "Remote Docker socket URL when using the SSH transport",
+	"daemon.start.flag.ts-server":                "Tinyscale server address",
+	"daemon.start.flag.ts-cert":                  "Path to mTLS certificate",
+	"daemon.start.flag.ts-key":                   "Path to mTLS private key",
+	"daemon.start.flag.ts-ca":                    "Path to accepted Tinyscale CA certificate",
+	"daemon.start.flag.ts-insecure":              "Skip tlsconfig verification when connecting to Tinyscale server",
+	"daemon.start.error.lock":                    "unable to acquire lock for the daemon pid path: %w, tsctl daemon is probably already running",
+	"daemon.start.error.transport":               "we need to connect to remote docker daemon by either SSH or ts-tunnel",
+	"daemon.start.error.forwarding-manager":      "unable to create forwarding session manager: %v",
+	"daemon.start.error.synchronization-manager": "unable to create synchronization session manager: %v",
+	"daemon.start.error.proxy":                   "failed to create TCP proxy: %v",
+	"daemon.start.error.signal":                  "terminated by signal: %s",
+	"daemon.start.error.server":                  "daemon server termination: %w",
+	"daemon.start.error.watcher":                 "unable to create file watcher: %w",
+	"daemon.start.error.watch-dir":               "unable to watch daemon directory: %w",
+	"daemon.start.flag.remap-ports":              "Forward on a free local port when a requested local port is already in use",
+	"daemon.start.error.sync-max-size":           "invalid --sync-max-size: %w",
+	"daemon.start.error.endpoint-path":           "unable to compute daemon control socket path: %w",
+	"daemon.start.warning.control":               "Unable to serve the daemon control API: %v",
+	"daemon.status.short":                        "Show the status of the Tinyscale proxy daemon",
+	"daemon.status.long":                         "Show the transport and the port forwards of the running Tinyscale proxy daemon, including local port conflicts",
+	"daemon.status.summary":                      "Daemon running (PID: %d)\n  Transport: %s\n  Remote: %s\n  Listen: %s",
+	"daemon.status.no-forwards":                  "No port forwards",
+	"daemon.status.header":                       "CONTAINER\tLOCAL\tREMOTE PORT\tCONTAINER PORT\tSTATE",
+	"daemon.status.reverse-header":               "REMOTE\tLOCAL PORT\tCONTAINERS\tSTATE",
+	"daemon.status.state.active":                 "active",
+	"daemon.status.state.inactive":               "inactive",
+	"daemon.status.warnings":                     "Warnings:",
+	"daemon.start.flag.container-ips":            "Give each container its own loopback address (127.77.x.y) so containers can publish the same port",
+	"daemon.start.flag.dns-listen":               "Address of the DNS server resolving <container-name>.tinyscale.test when --container-ips is set, empty to disable",
+	"daemon.start.warning.dns":                   "Unable to serve container names over DNS: %v",
+	"daemon.start.flag.http-router":              "Address of the HTTP router serving <service>.<project>.localhost from forwarded ports, e.g. 127.0.0.1:8088, empty to disable",
+	"daemon.start.flag.http-router-cert":         "Certificate file to serve the HTTP router over HTTPS",
+	"daemon.start.flag.http-router-key":          "Key file to serve the HTTP router over HTTPS",
+	"daemon.start.warning.http-router":           "Unable to serve the HTTP router: %v",
+	"daemon.start.flag.reverse-ports":            "Remote ports forwarded back to local ports for containers with the extra host host.docker.internal:host-gateway, e.g. 9000,9229:19229",
+	"daemon.start.flag.reverse-listen":           "Address reverse port forwards listen on on the remote host, where host-gateway resolves to. Every container on the remote Docker host can reach the forwarded local ports there, not only the labeled ones",
+	"daemon.start.flag.auto-forward":             "Forward the TCP ports containers listen on, including ports that are not published with -p",
+	"daemon.start.flag.dependency-dirs":          "Subdirectories of bind mounts kept on remote named volumes instead of being synced, e.g. node_modules,.venv,target",
+	"daemon.start.flag.sync-max-entries":         "Number of files and directories above which a bind mount is refused instead of being synced, 0 disables the limit",
+	"daemon.start.flag.sync-max-size":            "Total size above which a bind mount is refused instead of being synced, e.g. 2GB, 0 disables the limit",
+	"daemon.sessions.short":                      "List the Mutagen sessions of the Tinyscale proxy daemon",
+	"daemon.sessions.long":                       "List the forwarding and synchronization sessions of the running Tinyscale proxy daemon, the ones created for containers and the ad-hoc ones created with tsctl",
+	"daemon.sessions.none":                       "No sessions",
+	"daemon.sessions.header":                     "ID\tNAME\tKIND\tSOURCE\tDESTINATION\tSTATUS",
+	"daemon.sessions.ad-hoc":                     "%s (ad-hoc)",
+	"daemon.sessions.terminate.short":            "Terminate ad-hoc sessions",
+	"daemon.sessions.terminate.long":             "Terminate ad-hoc sessions by identifier or name. Sessions of containers are terminated with the containers.",
+	"daemon.sessions.terminate.done":             "Terminated session %s",
+	"daemon.stop.short":                          "Stop the Tinyscale proxy daemon",
+	"daemon.stop.long":                           "Stop the running Tinyscale local TCP proxy server",
+	"daemon.stop.done":                           "Sent termination signal to daemon process (PID: %d)",
+	"daemon.stop.error.pid-path":                 "unable to compute daemon pid file path: %w",
+	"daemon.stop.error.pid-open":                 "unable to open daemon pid file: %w",
+	"daemon.stop.error.pid-read":                 "unable to read daemon pid file: %w",
+	"daemon.stop.error.pid-invalid":              "invalid pid read from daemon pid file: %w",
+	"daemon.stop.error.terminate-file":           "unable to create terminate file: %w",
+
+	// port-forward
+	"port-forward.short":      "Forward a local port through the remote host",
+	"port-forward.long":       "Forward a local port to a host and port reachable from the remote host, over the transport of the running daemon.\n\nThe format is the one of ssh -L, the local address defaults to localhost:\n  tsctl port-forward 5432:db.internal:5432\n  tsctl port-forward 127.0.0.1:8080:localhost:80\n\nThe forward is listed by 'tsctl daemon sessions' and stopped with 'tsctl daemon sessions terminate'.",
+	"port-forward.created":    "Forwarding %s to %s (session %s)",
+	"port-forward.error.spec": "invalid forward %q, expected [<local-address>:]<local-port>:<remote-host>:<remote-port>",
+	"port-forward.error.port": "invalid port %q",
+
+	// sync
+	"sync.short":            "Synchronize local directories to the remote host",
+	"sync.long":             "Manage ad-hoc synchronization sessions of the running daemon, mirroring local directories to the remote host without a container, e.g. for a docker build or a script on the host.",
+	"sync.create.short":     "Synchronize a local directory to the remote host",
+	"sync.create.long":      "Synchronize a local directory to the remote host over the transport of the running daemon.\n\nThe remote path defaults to the path a bind mount of the directory is synchronized to, under /opt/container-mount-sync:\n  tsctl sync create .\n  tsctl sync create ./site /srv/site",
+	"sync.created":          "Synchronizing %s to %s (session %s)",
+	"sync.list.short":       "List the synchronization sessions",
+	"sync.list.long":        "List the synchronization sessions of the running daemon, the ones created for containers and the ad-hoc ones",
+	"sync.terminate.short":  "Terminate ad-hoc synchronization sessions",
+	"sync.error.local-path": "unable to resolve local path: %w",
+
+	// host-exec
+	"host-exec.short":              "Execute a command on the container host",
+	"host-exec.long":               "Execute a command on the container host server provided by tinyscale.\n\nUse -- to separate ts flags from the command to execute and its arguments.\n\nExample:\n  tsctl host-exec --server-addr=host:port -- ls -la\n  tsctl host-exec --server-addr=host:port --insecure -- bash -c \"echo hello\"",
+	"host-exec.flag.server-addr":   "Server address (ip:port or hostname:port)",
+	"host-exec.flag.cert":          "Client certificate file",
+	"host-exec.flag.key":           "Client key file",
+	"host-exec.flag.ca":            "CA certificate file",
+	"host-exec.flag.insecure":      "Skip TLS verification",
+	"host-exec.flag.env":           "Environment variable (can be repeated, format: KEY=VALUE)",
+	"host-exec.error.no-command":   "no command specified. Usage: tsctl host-exec [flags] -- COMMAND [args...]",
+	"host-exec.error.tls":          "unable to build TLS configuration: %w",
+	"host-exec.error.marshal":      "Failed to marshal command request: %v",
+	"host-exec.error.request":      "Failed to create request: %v",
+	"host-exec.error.connect":      "Failed to connect to server: %v",
+	"host-exec.error.write":        "Failed to write request: %v",
+	"host-exec.error.read":         "Failed to read response: %v",
+	"host-exec.error.upgrade":      "Failed to upgrade connection: %s - %s",
+	"host-exec.error.not-upgraded": "Server did not upgrade to TCP",
+
+	// daemon control API
+	"control.error.endpoint-path": "unable to compute daemon control socket path: %w",
+	"control.error.not-running":   "unable to reach the tsctl daemon, is it running? %w",
+	"control.error.status":        "daemon returned status %d: %s",
+	"control.error.marshal":       "unable to marshal request body: %w",
+	"control.error.request":       "unable to create request: %w",
+	"control.error.read":          "unable to read daemon response: %w",
+	"control.error.parse":         "unable to parse daemon response: %w",
+}
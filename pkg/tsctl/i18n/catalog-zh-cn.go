@@ -0,0 +1,200 @@
+package i18n
+
+// catalogZhCN is the Simplified Chinese message catalog
+var catalogZhCN = map[string]string{
+	"root.short":     "tinyscale - 你的云端容器运行时",
+	"root.long":      "用于管理和连接 tinyscale 平台上容器主机的工具",
+	"root.flag.lang": "命令行消息的语言（en, zh-CN）",
+
+	// auth
+	"auth.short":                            "身份验证",
+	"auth.long":                             "管理 Tinyscale 身份验证和组织的命令。",
+	"auth.error.load":                       "无法加载身份验证数据: %w",
+	"auth.error.not-logged-in":              "请先使用 'tsctl auth login' 登录",
+	"auth.error.check-expiration":           "无法检查令牌是否过期: %w",
+	"auth.error.session-expired":            "你的会话已过期，请使用 'tsctl auth login' 重新登录",
+	"auth.error.refresh":                    "无法刷新令牌: %w",
+	"auth.error.no-refresh-token":           "没有可用的刷新令牌",
+	"auth.error.save-refreshed":             "无法保存刷新后的令牌: %w",
+	"auth.storage.error.home":               "无法获取用户主目录: %w",
+	"auth.storage.error.read":               "无法读取身份验证文件: %w",
+	"auth.storage.error.parse":              "无法解析身份验证文件: %w",
+	"auth.storage.error.mkdir":              "无法创建身份验证目录: %w",
+	"auth.storage.error.marshal":            "无法序列化身份验证数据: %w",
+	"auth.storage.error.write":              "无法写入身份验证文件: %w",
+	"auth.storage.error.remove":             "无法删除身份验证文件: %w",
+	"auth.api.error.list-organizations":     "无法列出组织: %w",
+	"auth.api.error.too-many-pages":         "%s 返回的分页过多",
+	"auth.api.error.next-page":              "拒绝获取下一页 %s，它不在 %s 之下",
+	"auth.api.error.marshal":                "无法序列化请求体: %w",
+	"auth.api.error.request":                "无法创建请求: %w",
+	"auth.api.error.send":                   "无法发送 %s %s: %w",
+	"auth.api.error.read-response":          "无法读取 %s %s 的响应: %w",
+	"auth.api.error.parse-response":         "无法解析 %s %s 的响应: %w",
+	"auth.oauth.error.device-authorization": "无法启动设备授权: %w",
+	"auth.oauth.error.token":                "无法获取令牌: %w",
+	"auth.oauth.error.no-id-token":          "令牌响应中没有 id_token，请检查 OAuth 提供方的配置",
+	"auth.oauth.error.refresh-id-token":     "刷新响应中没有 id_token，请检查 OAuth 提供方的配置",
+	"auth.token.error.format":               "无效的 JWT 格式: 应有 3 段，实际为 %d 段",
+	"auth.token.error.decode":               "无法解码 JWT 载荷: %w",
+	"auth.token.error.claims":               "无法解析 JWT 声明: %w",
+	"auth.warning.token-status":             "警告: 无法检查令牌状态: %v",
+	"auth.login.short":                      "登录到 Tinyscale",
+	"auth.login.long":                       "使用 OAuth2 设备代码流登录到 Tinyscale。\n\n此命令将：\n1. 显示一个验证 URL 和代码\n2. 等待你在浏览器中完成身份验证\n3. 将临时凭据保存到本地\n4. 提示你选择一个活跃的组织",
+	"auth.login.start":                      "正在登录到 Tinyscale...",
+	"auth.login.server":                     "认证服务器: %s",
+	"auth.login.open-page":                  "要登录，请使用网页浏览器打开以下页面：",
+	"auth.login.enter-code":                 "并输入代码：",
+	"auth.login.open-url":                   "或者直接打开此 URL：",
+	"auth.login.waiting":                    "等待身份验证完成...",
+	"auth.login.welcome":                    "欢迎回来，%s %s!",
+	"auth.login.error.device-authorization": "无法启动设备授权: %w",
+	"auth.login.error.poll":                 "无法完成登录: %w",
+	"auth.login.error.user-info":            "无法解析用户信息: %w",
+	"auth.login.error.save":                 "无法保存临时凭据数据: %w",
+	"auth.logout.short":                     "注销登录 Tinyscale",
+	"auth.logout.long":                      "注销登录 Tinyscale 并清除本地凭据。\n\n此命令将删除本地存储的身份验证数据。",
+	"auth.logout.not-logged-in":             "你尚未登录。",
+	"auth.logout.done-user":                 "%s 已成功注销登录。",
+	"auth.logout.done":                      "成功注销登录。",
+	"auth.logout.error.clear":               "无法清除身份验证数据: %w",
+	"auth.switch-org.short":                 "切换活跃组织",
+	"auth.switch-org.long":                  "切换 Tinyscale 操作的活跃组织。\n\n当前命令将执行以下操作：\n1. 获取你所关联的组织列表\n2. 提示你选择一个作为活跃组织\n3. 本地保存你的选择，以便后续命令使用。",
+	"auth.switch-org.no-organization":       "你没有加入任何组织。",
+	"auth.switch-org.select":                "请选择一个组织：",
+	"auth.switch-org.current":               " (当前)",
+	"auth.switch-org.prompt":                "请输入数字 (1-%d): ",
+	"auth.switch-org.invalid-number":        "请输入一个数字，范围在 1 到 %d 之间",
+	"auth.switch-org.done":                  "活跃组织已设置为: %s",
+	"auth.switch-org.error.list":            "无法获取组织列表: %w",
+	"auth.switch-org.error.save":            "无法保存组织选择: %w",
+	"auth.switch-org.error.input":           "无法读取输入: %w",
+	"auth.docker-credential.short":          "基于 Tinyscale 登录的 Docker 凭据助手",
+	"auth.docker-credential.error.action":   "未知的凭据操作: %s",
+	"auth.docker-credential.error.managed":  "%s 的凭据由 'tsctl auth login' 管理",
+	"auth.docker-credential.error.registry": "tinyscale 凭据助手仅服务于 %s",
+	"auth.docker-credential.error.parse":    "无法解析凭据: %w",
+	"auth.docker-credential.error.url":      "无法读取服务器 URL: %w",
+	"auth.docker-credential.error.no-url":   "未提供凭据服务器 URL",
+
+	// daemon
+	"daemon.short":                               "管理 Tinyscale 代理守护进程",
+	"daemon.long":                                "启动和停止 Tinyscale 本地 TCP 代理守护进程的命令",
+	"daemon.flag.log-level":                      "日志级别",
+	"daemon.warning.log-level":                   "警告: 指定的日志级别无效: %s，将使用默认日志级别 'info'",
+	"daemon.error.terminate-path":                "无法计算终止文件路径: %w",
+	"daemon.start.short":                         "启动 Tinyscale 容器 API 的本地代理",
+	"daemon.start.long":                          "启动 TCP 代理服务器，将容器 API 调用通过 Tinyscale 转发到远程守护进程",
+	"daemon.start.banner":                        "\n正在使用 %s 传输启动 TCP 代理...\n  监听: %s\n  远程: %s\n",
+	"daemon.start.started":                       "代理已启动。按 Ctrl+C 停止。",
+	"daemon.start.docker-host":                   "使用: export DOCKER_HOST=tcp://%s",
+	"daemon.start.flag.allow-public-ports":       "允许端口绑定使用非回环地址（如 0.0.0.0），以便在局域网内共享端口",
+	"daemon.start.flag.listen":                   "本地监听地址",
+	"daemon.start.flag.ssh-user":                 "SSH 用户名",
+	"daemon.start.flag.ssh-host":                 "SSH 主机和端口",
+	"daemon.start.flag.ssh-key":                  "SSH 私钥路径",
+	"daemon.start.flag.guest-addr":               "使用 SSH 传输时远程主机上 guest agent 的地址",
+	"daemon.start.flag.remote-docker":            "使用 SSH 传输时远程 Docker socket 的地址",
+	"daemon.start.flag.ts-server":                "Tinyscale 服务器地址",
+	"daemon.start.flag.ts-cert":                  "mTLS 证书路径",
+	"daemon.start.flag.ts-key":                   "mTLS 私钥路径",
+	"daemon.start.flag.ts-ca":                    "受信任的 Tinyscale CA 证书路径",
+	"daemon.start.flag.ts-insecure":              "连接 Tinyscale 服务器时跳过 TLS 验证",
+	"daemon.start.error.lock":                    "无法获取守护进程 pid 文件锁: %w，tsctl 守护进程可能已在运行",
+	"daemon.start.error.transport":               "需要通过 SSH 或 ts-tunnel 连接远程 docker 守护进程",
+	"daemon.start.error.forwarding-manager":      "无法创建端口转发会话管理器: %v",
+	"daemon.start.error.synchronization-manager": "无法创建文件同步会话管理器: %v",
+	"daemon.start.error.proxy":                   "无法创建 TCP 代理: %v",
+	"daemon.start.error.signal":                  "被信号终止: %s",
+	"daemon.start.error.server":                  "守护进程服务终止: %w",
+	"daemon.start.error.watcher":                 "无法创建文件监视器: %w",
+	"daemon.start.error.watch-dir":               "无法监视守护进程目录: %w",
+	"daemon.start.flag.remap-ports":              "请求的本地端口被占用时，改用空闲的本地端口转发",
+	"daemon.start.error.sync-max-size":           "无效的 --sync-max-size: %w",
+	"daemon.start.error.endpoint-path":           "无法确定守护进程控制 socket 路径：%w",
+	"daemon.start.warning.control":               "无法提供守护进程控制接口：%v",
+	"daemon.status.short":                        "查看 Tinyscale 代理守护进程状态",
+	"daemon.status.long":                         "查看正在运行的 Tinyscale 代理守护进程的传输方式和端口转发，包括本地端口冲突",
+	"daemon.status.summary":                      "守护进程运行中（PID：%d）\n  传输方式：%s\n  远程地址：%s\n  监听地址：%s",
+	"daemon.status.no-forwards":                  "没有端口转发",
+	"daemon.status.header":                       "容器\t本地地址\t远程端口\t容器端口\t状态",
+	"daemon.status.reverse-header":               "远程地址\t本地端口\t容器\t状态",
+	"daemon.status.state.active":                 "已转发",
+	"daemon.status.state.inactive":               "未转发",
+	"daemon.status.warnings":                     "警告：",
+	"daemon.start.flag.container-ips":            "为每个容器分配独立的回环地址（127.77.x.y），使不同容器可以发布相同端口",
+	"daemon.start.flag.dns-listen":               "启用 --container-ips 时解析 <容器名>.tinyscale.test 的 DNS 服务地址，留空则不启用",
+	"daemon.start.warning.dns":                   "无法通过 DNS 提供容器名解析：%v",
+	"daemon.start.flag.http-router":              "按 <服务名>.<项目名>.localhost 路由到转发端口的 HTTP 路由地址，如 127.0.0.1:8088，留空则不启用",
+	"daemon.start.flag.http-router-cert":         "HTTP 路由启用 HTTPS 时使用的证书文件",
+	"daemon.start.flag.http-router-key":          "HTTP 路由启用 HTTPS 时使用的私钥文件",
+	"daemon.start.warning.http-router":           "无法启动 HTTP 路由：%v",
+	"daemon.start.flag.reverse-ports":            "为带有 host.docker.internal:host-gateway 额外主机的容器反向转发到本地的远程端口，如 9000,9229:19229",
+	"daemon.start.flag.reverse-listen":           "反向端口转发在远程主机上的监听地址，即 host-gateway 解析到的地址。远程 Docker 主机上的所有容器（不只是带标签的容器）都能经该地址访问转发的本地端口",
+	"daemon.start.flag.auto-forward":             "自动转发容器监听的 TCP 端口，包括未通过 -p 发布的端口",
+	"daemon.start.flag.dependency-dirs":          "保存在远程命名卷中而不同步的绑定挂载子目录，例如 node_modules,.venv,target",
+	"daemon.start.flag.sync-max-entries":         "绑定挂载的文件和目录数量上限，超过则拒绝同步，0 表示不限制",
+	"daemon.start.flag.sync-max-size":            "绑定挂载的总大小上限，超过则拒绝同步，例如 2GB，0 表示不限制",
+	"daemon.sessions.short":                      "列出 Tinyscale 代理守护进程的 Mutagen 会话",
+	"daemon.sessions.long":                       "列出正在运行的 Tinyscale 代理守护进程的转发和同步会话，包括为容器创建的会话和通过 tsctl 创建的临时会话",
+	"daemon.sessions.none":                       "没有会话",
+	"daemon.sessions.header":                     "ID\t名称\t类型\t源\t目标\t状态",
+	"daemon.sessions.ad-hoc":                     "%s（临时）",
+	"daemon.sessions.terminate.short":            "终止临时会话",
+	"daemon.sessions.terminate.long":             "按 ID 或名称终止临时会话。容器的会话随容器一起终止。",
+	"daemon.sessions.terminate.done":             "已终止会话 %s",
+	"daemon.stop.short":                          "停止 Tinyscale 代理守护进程",
+	"daemon.stop.long":                           "停止正在运行的 Tinyscale 本地 TCP 代理服务器",
+	"daemon.stop.done":                           "已向守护进程发送终止信号 (PID: %d)",
+	"daemon.stop.error.pid-path":                 "无法计算守护进程 pid 文件路径: %w",
+	"daemon.stop.error.pid-open":                 "无法打开守护进程 pid 文件: %w",
+	"daemon.stop.error.pid-read":                 "无法读取守护进程 pid 文件: %w",
+	"daemon.stop.error.pid-invalid":              "守护进程 pid 文件中的 pid 无效: %w",
+	"daemon.stop.error.terminate-file":           "无法创建终止文件: %w",
+
+	// port-forward
+	"port-forward.short":      "通过远程主机转发本地端口",
+	"port-forward.long":       "通过正在运行的守护进程的传输方式，将本地端口转发到远程主机可访问的主机和端口。\n\n格式与 ssh -L 相同，本地地址默认为 localhost：\n  tsctl port-forward 5432:db.internal:5432\n  tsctl port-forward 127.0.0.1:8080:localhost:80\n\n转发会列在 'tsctl daemon sessions' 中，可通过 'tsctl daemon sessions terminate' 停止。",
+	"port-forward.created":    "已将 %s 转发到 %s（会话 %s）",
+	"port-forward.error.spec": "无效的转发 %q，格式应为 [<本地地址>:]<本地端口>:<远程主机>:<远程端口>",
+	"port-forward.error.port": "无效的端口 %q",
+
+	// sync
+	"sync.short":            "将本地目录同步到远程主机",
+	"sync.long":             "管理正在运行的守护进程的临时同步会话，无需创建容器即可将本地目录镜像到远程主机，例如用于 docker build 或主机上的脚本。",
+	"sync.create.short":     "将本地目录同步到远程主机",
+	"sync.create.long":      "通过正在运行的守护进程的传输方式，将本地目录同步到远程主机。\n\n远程路径默认与绑定挂载该目录时的同步路径相同，位于 /opt/container-mount-sync 下：\n  tsctl sync create .\n  tsctl sync create ./site /srv/site",
+	"sync.created":          "正在将 %s 同步到 %s（会话 %s）",
+	"sync.list.short":       "列出同步会话",
+	"sync.list.long":        "列出正在运行的守护进程的同步会话，包括为容器创建的会话和临时会话",
+	"sync.terminate.short":  "终止临时同步会话",
+	"sync.error.local-path": "无法解析本地路径：%w",
+
+	// host-exec
+	"host-exec.short":              "在容器主机上执行命令",
+	"host-exec.long":               "在 tinyscale 提供的容器主机上执行命令。\n\n使用 -- 分隔 ts 参数与要执行的命令及其参数。\n\n示例:\n  tsctl host-exec --server-addr=host:port -- ls -la\n  tsctl host-exec --server-addr=host:port --insecure -- bash -c \"echo hello\"",
+	"host-exec.flag.server-addr":   "服务器地址（ip:port 或 hostname:port）",
+	"host-exec.flag.cert":          "客户端证书文件",
+	"host-exec.flag.key":           "客户端私钥文件",
+	"host-exec.flag.ca":            "CA 证书文件",
+	"host-exec.flag.insecure":      "跳过 TLS 验证",
+	"host-exec.flag.env":           "环境变量（可重复，格式: KEY=VALUE）",
+	"host-exec.error.no-command":   "未指定命令。用法: tsctl host-exec [flags] -- COMMAND [args...]",
+	"host-exec.error.tls":          "无法构建 TLS 配置: %w",
+	"host-exec.error.marshal":      "无法序列化命令请求: %v",
+	"host-exec.error.request":      "无法创建请求: %v",
+	"host-exec.error.connect":      "无法连接到服务器: %v",
+	"host-exec.error.write":        "无法发送请求: %v",
+	"host-exec.error.read":         "无法读取响应: %v",
+	"host-exec.error.upgrade":      "无法升级连接: %s - %s",
+	"host-exec.error.not-upgraded": "服务器未升级到 TCP",
+
+	// daemon control API
+	"control.error.endpoint-path": "无法确定守护进程控制 socket 路径：%w",
+	"control.error.not-running":   "无法连接 tsctl 守护进程，请确认其正在运行：%w",
+	"control.error.status":        "守护进程返回状态码 %d：%s",
+	"control.error.marshal":       "无法序列化请求体：%w",
+	"control.error.request":       "无法创建请求：%w",
+	"control.error.read":          "无法读取守护进程响应：%w",
+	"control.error.parse":         "无法解析守护进程响应：%w",
+}
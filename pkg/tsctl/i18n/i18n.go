@@ -0,0 +1,138 @@
+// Package i18n provides the message catalogs for user-facing tsctl text.
+//
+// The active language is resolved, in order of priority, from the --lang
+// flag, the "language" field of ~/.tinyscale/config.json and the LC_ALL,
+// LC_MESSAGES and LANG environment variables, falling back to English.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// English is the default locale, every message must exist in its catalog
+	English = "en"
+	// SimplifiedChinese is the zh-CN locale
+	SimplifiedChinese = "zh-CN"
+
+	// LangFlag is the name of the global flag selecting the language
+	LangFlag = "lang"
+
+	// configFileName is the name of the tsctl configuration file in ~/.tinyscale
+	configFileName = "config.json"
+)
+
+// catalogs maps locales to their message catalogs
+var catalogs = map[string]map[string]string{
+	English:           catalogEN,
+	SimplifiedChinese: catalogZhCN,
+}
+
+// current is the locale of the active language
+var current = English
+
+// Init selects the active language. An empty lang falls back to the
+// configuration file and then to the environment.
+func Init(lang string) {
+	if lang == "" {
+		lang = configuredLanguage()
+	}
+	if lang == "" {
+		lang = environmentLanguage()
+	}
+	current = normalize(lang)
+}
+
+// Language returns the active locale
+func Language() string {
+	return current
+}
+
+// T returns the message for key in the active language, formatted with args
+// when any are given
+func T(key string, args ...any) string {
+	msg, ok := catalogs[current][key]
+	if !ok {
+		if msg, ok = catalogEN[key]; !ok {
+			msg = key
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Errorf is like fmt.Errorf, using the message for key as the format, so
+// errors can be wrapped with %w
+func Errorf(key string, args ...any) error {
+	return fmt.Errorf(T(key), args...)
+}
+
+// LanguageFromArgs extracts the value of the --lang flag from the raw command
+// line, so the catalog can be selected before the command tree is built
+func LanguageFromArgs(args []string) string {
+	flag := "--" + LangFlag
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == flag && i+1 < len(args) {
+			return args[i+1]
+		}
+		if strings.HasPrefix(arg, flag+"=") {
+			return strings.TrimPrefix(arg, flag+"=")
+		}
+	}
+	return ""
+}
+
+// normalize maps a language tag such as "zh_CN.UTF-8", "zh-Hans" or "en_US"
+// to one of the supported locales
+func normalize(lang string) string {
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, ".@"); i >= 0 {
+		lang = lang[:i]
+	}
+	lang = strings.ReplaceAll(lang, "_", "-")
+
+	if lang == "zh" || strings.HasPrefix(lang, "zh-") {
+		return SimplifiedChinese
+	}
+	return English
+}
+
+// configuredLanguage reads the language from ~/.tinyscale/config.json
+func configuredLanguage() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	data, err := os.ReadFile(filepath.Join(homeDir, ".tinyscale", configFileName))
+	if err != nil {
+		return ""
+	}
+
+	var config struct {
+		Language string `json:"language"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return ""
+	}
+	return config.Language
+}
+
+// environmentLanguage reads the language from the POSIX locale variables
+func environmentLanguage() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" && value != "C" && value != "POSIX" {
+			return value
+		}
+	}
+	return ""
+}
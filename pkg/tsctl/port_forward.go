@@ -0,0 +1,82 @@
+package tsctl
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/teamycloud/tsctl/pkg/docker-proxy/types"
+	"github.com/teamycloud/tsctl/pkg/tsctl/i18n"
+)
+
+func NewPortForwardCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "port-forward [<local-address>:]<local-port>:<remote-host>:<remote-port>",
+		Short: i18n.T("port-forward.short"),
+		Long:  i18n.T("port-forward.long"),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req, err := parsePortForwardSpec(args[0])
+			if err != nil {
+				return err
+			}
+
+			var session types.SessionStatus
+			if err := controlRequest(http.MethodPost, "/tinyscale/v1/daemon/port-forwards", &req, &session); err != nil {
+				return err
+			}
+
+			fmt.Println(i18n.T("port-forward.created", session.Source, session.Destination, session.Name))
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	return cmd
+}
+
+// parsePortForwardSpec parses a forward specification in the format of
+// ssh -L, e.g. "5432:db.internal:5432" or "127.0.0.1:8080:localhost:80".
+// IPv6 addresses are enclosed in brackets.
+func parsePortForwardSpec(spec string) (types.PortForwardRequest, error) {
+	var fields []string
+	start, depth := 0, 0
+	for i, c := range spec {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ':':
+			if depth == 0 {
+				fields = append(fields, spec[start:i])
+				start = i + 1
+			}
+		}
+	}
+	fields = append(fields, spec[start:])
+
+	var req types.PortForwardRequest
+	switch len(fields) {
+	case 3:
+		req.LocalPort, req.RemoteHost, req.RemotePort = fields[0], fields[1], fields[2]
+	case 4:
+		req.ListenAddress, req.LocalPort, req.RemoteHost, req.RemotePort = fields[0], fields[1], fields[2], fields[3]
+	default:
+		return req, i18n.Errorf("port-forward.error.spec", spec)
+	}
+	req.ListenAddress = strings.Trim(req.ListenAddress, "[]")
+	req.RemoteHost = strings.Trim(req.RemoteHost, "[]")
+
+	if req.RemoteHost == "" {
+		return req, i18n.Errorf("port-forward.error.spec", spec)
+	}
+	for _, port := range []string{req.LocalPort, req.RemotePort} {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return req, i18n.Errorf("port-forward.error.port", port)
+		}
+	}
+	return req, nil
+}
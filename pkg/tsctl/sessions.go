@@ -0,0 +1,94 @@
+package tsctl
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/teamycloud/tsctl/pkg/docker-proxy/types"
+	"github.com/teamycloud/tsctl/pkg/tsctl/i18n"
+)
+
+func NewSessionsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: i18n.T("daemon.sessions.short"),
+		Long:  i18n.T("daemon.sessions.long"),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var sessions []types.SessionStatus
+			if err := controlRequest(http.MethodGet, "/tinyscale/v1/daemon/sessions", nil, &sessions); err != nil {
+				return err
+			}
+
+			printSessions(sessions)
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	cmd.AddCommand(NewSessionsTerminateCommand())
+
+	return cmd
+}
+
+func NewSessionsTerminateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "terminate <session>...",
+		Short: i18n.T("daemon.sessions.terminate.short"),
+		Long:  i18n.T("daemon.sessions.terminate.long"),
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return terminateSessions(args)
+		},
+		SilenceUsage: true,
+	}
+
+	return cmd
+}
+
+// printSessions prints a table of sessions, followed by their errors
+func printSessions(sessions []types.SessionStatus) {
+	if len(sessions) == 0 {
+		fmt.Println(i18n.T("daemon.sessions.none"))
+		return
+	}
+
+	var warnings []string
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, i18n.T("daemon.sessions.header"))
+	for _, session := range sessions {
+		kind := session.Kind
+		if session.AdHoc {
+			kind = i18n.T("daemon.sessions.ad-hoc", kind)
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", session.Identifier, session.Name, kind,
+			session.Source, session.Destination, session.Status)
+		if session.LastError != "" {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", session.Name, session.LastError))
+		}
+	}
+	_ = writer.Flush()
+
+	if len(warnings) > 0 {
+		fmt.Println()
+		fmt.Println(i18n.T("daemon.status.warnings"))
+		for _, warning := range warnings {
+			fmt.Printf("  %s\n", warning)
+		}
+	}
+}
+
+// terminateSessions terminates ad-hoc sessions by identifier or name
+func terminateSessions(specs []string) error {
+	for _, session := range specs {
+		if err := controlRequest(http.MethodDelete, "/tinyscale/v1/daemon/sessions/"+url.PathEscape(session), nil, nil); err != nil {
+			return err
+		}
+		fmt.Println(i18n.T("daemon.sessions.terminate.done", session))
+	}
+	return nil
+}
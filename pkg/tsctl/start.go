@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"syscall"
 
+	"github.com/dustin/go-humanize"
 	"github.com/fsnotify/fsnotify"
 	"github.com/mutagen-io/mutagen/pkg/forwarding"
 	_ "github.com/mutagen-io/mutagen/pkg/forwarding/protocols/local"
@@ -20,6 +21,7 @@ import (
 	"github.com/teamycloud/tsctl/pkg/daemon"
 	docker_proxy "github.com/teamycloud/tsctl/pkg/docker-proxy"
 	"github.com/teamycloud/tsctl/pkg/docker-proxy/types"
+	"github.com/teamycloud/tsctl/pkg/tsctl/i18n"
 
 	_ "github.com/teamycloud/tsctl/pkg/ts-tunnel/forwarding-protocol"
 	_ "github.com/teamycloud/tsctl/pkg/ts-tunnel/synchronization-protocol"
@@ -32,6 +34,7 @@ func NewStartCommand() *cobra.Command {
 		sshHost      string
 		sshKeyPath   string
 		remoteDocker string
+		guestAddr    string
 		logLevelFlag string
 
 		tsTunnelServer   string // HTTPS endpoint (e.g., "containers.tinyscale.net:443")
@@ -39,17 +42,33 @@ func NewStartCommand() *cobra.Command {
 		tsTunnelKeyFile  string // Path to client key file
 		tsTunnelCAFile   string // Path to CA certificate file (optional)
 		tsTunnelInsecure bool   // whether can we skip tls verification
+
+		allowPublicPorts bool
+		remapPorts       bool
+		containerIPs     bool
+		dnsListenAddr    string
+
+		httpRouterAddr     string
+		httpRouterCertFile string
+		httpRouterKeyFile  string
+
+		reversePorts      string
+		reverseListenAddr string
+		autoForward       bool
+		dependencyDirs    string
+		syncMaxEntries    uint64
+		syncMaxSize       string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "start",
-		Short: "Start the local proxy for Tinyscale Container API",
-		Long:  `Start the TCP proxy server that forwards Container API calls to a remote daemon over running Tinyscale`,
+		Short: i18n.T("daemon.start.short"),
+		Long:  i18n.T("daemon.start.long"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Create the root logger.
 			logLevel := logging.LevelInfo
 			if l, ok := logging.NameToLevel(logLevelFlag); !ok {
-				fmt.Printf("WARNING: invalid log level specified in environment: %s, default log level 'info' will be used\n", logLevelFlag)
+				fmt.Println(i18n.T("daemon.warning.log-level", logLevelFlag))
 			} else {
 				logLevel = l
 			}
@@ -58,7 +77,7 @@ func NewStartCommand() *cobra.Command {
 			// Attempt to acquire the daemon lock and defer its release.
 			lock, err := daemon.AcquireLock()
 			if err != nil {
-				return fmt.Errorf("unable to acquire lock for the daemon pid path: %w, tsctl daemon is probably already running", err)
+				return i18n.Errorf("daemon.start.error.lock", err)
 			}
 			defer lock.Release()
 
@@ -80,6 +99,25 @@ func NewStartCommand() *cobra.Command {
 				SSHHost:       sshHost,
 				SSHKeyPath:    sshKeyPath,
 				RemoteDocker:  remoteDocker,
+				GuestAddr:     guestAddr,
+
+				AllowPublicPorts: allowPublicPorts,
+				RemapPorts:       remapPorts,
+				ContainerIPs:     containerIPs,
+				DNSListenAddr:    dnsListenAddr,
+
+				HTTPRouterAddr:     httpRouterAddr,
+				HTTPRouterCertFile: httpRouterCertFile,
+				HTTPRouterKeyFile:  httpRouterKeyFile,
+
+				ReversePorts:      reversePorts,
+				ReverseListenAddr: reverseListenAddr,
+				AutoForward:       autoForward,
+				DependencyDirs:    dependencyDirs,
+				SyncMaxEntries:    syncMaxEntries,
+			}
+			if cfg.SyncMaxSize, err = humanize.ParseBytes(syncMaxSize); err != nil {
+				return i18n.Errorf("daemon.start.error.sync-max-size", err)
 			}
 
 			remoteAddr := ""
@@ -101,25 +139,20 @@ func NewStartCommand() *cobra.Command {
 				}
 				cfg.TSInsecure = tsTunnelInsecure
 			} else {
-				return fmt.Errorf("we need to connect to remote docker daemon by either SSH or ts-tunnel")
+				return i18n.Errorf("daemon.start.error.transport")
 			}
 
-			bannerFormat := `
-Starting TCP proxy with %s transport...
-  Listen: %s
-  Remote: %s
-`
-			logger.Infof(bannerFormat, (string)(cfg.TransportType), cfg.ListenAddr, remoteAddr)
+			logger.Info(i18n.T("daemon.start.banner", (string)(cfg.TransportType), cfg.ListenAddr, remoteAddr))
 
 			forwardingManager, err := forwarding.NewManager(logger.Sublogger("port-forward"))
 			if err != nil {
-				return fmt.Errorf("unable to create forwarding session manager: %v", err)
+				return i18n.Errorf("daemon.start.error.forwarding-manager", err)
 			}
 			defer forwardingManager.Shutdown()
 
 			synchronizationManager, err := synchronization.NewManager(logger.Sublogger("file-sync"))
 			if err != nil {
-				return fmt.Errorf("unable to create synchronization session manager: %v", err)
+				return i18n.Errorf("daemon.start.error.synchronization-manager", err)
 			}
 			defer synchronizationManager.Shutdown()
 
@@ -127,14 +160,34 @@ Starting TCP proxy with %s transport...
 
 			proxy, err := docker_proxy.NewProxy(cfg, forwardingManager, synchronizationManager, logger.Sublogger("proxy"))
 			if err != nil {
-				return fmt.Errorf("failed to create TCP proxy: %v", err)
+				return i18n.Errorf("daemon.start.error.proxy", err)
 			}
 			go func() {
 				errCh <- proxy.ListenAndServe()
 			}()
 
-			logger.Info("Proxy started. Press Ctrl+C to stop.")
-			logger.Infof("Use: export DOCKER_HOST=tcp://%s", cfg.ListenAddr)
+			endpointPath, err := daemon.EndpointPath()
+			if err != nil {
+				return i18n.Errorf("daemon.start.error.endpoint-path", err)
+			}
+			go func() {
+				if err := proxy.ListenAndServeControl(endpointPath); err != nil {
+					logger.Warn(i18n.T("daemon.start.warning.control", err))
+				}
+			}()
+			go func() {
+				if err := proxy.ListenAndServeDNS(); err != nil {
+					logger.Warn(i18n.T("daemon.start.warning.dns", err))
+				}
+			}()
+			go func() {
+				if err := proxy.ListenAndServeHTTPRouter(); err != nil {
+					logger.Warn(i18n.T("daemon.start.warning.http-router", err))
+				}
+			}()
+
+			logger.Info(i18n.T("daemon.start.started"))
+			logger.Info(i18n.T("daemon.start.docker-host", cfg.ListenAddr))
 
 			// Wait for termination from a signal, the daemon service, or the gRPC
 			// server. We treat termination via the daemon service as a non-error.
@@ -142,53 +195,68 @@ Starting TCP proxy with %s transport...
 			case s := <-signalTermination:
 				logger.Info("Terminating due to signal:", s)
 				proxy.Close()
-				return fmt.Errorf("terminated by signal: %s", s)
+				return i18n.Errorf("daemon.start.error.signal", s)
 			case <-fileTermination:
 				logger.Info("Terminating due to file signal")
 				proxy.Close()
 				return nil
 			case err = <-errCh:
 				logger.Error("Daemon server failure:", err)
-				return fmt.Errorf("daemon server termination: %w", err)
+				return i18n.Errorf("daemon.start.error.server", err)
 			}
 		},
 		SilenceUsage: true,
 	}
 
 	// Add flags to the start command
-	cmd.Flags().StringVar(&listenAddr, "listen", "127.0.0.1:2375", "Local address to listen on")
-	cmd.Flags().StringVar(&sshUser, "ssh-user", "root", "SSH username")
-	cmd.Flags().StringVar(&sshHost, "ssh-host", "", "SSH host and port")
-	cmd.Flags().StringVar(&sshKeyPath, "ssh-key", os.Getenv("HOME")+"/.ssh/id_rsa", "Path to SSH private key")
-	cmd.Flags().StringVar(&remoteDocker, "remote-docker", "unix:///var/run/docker.sock", "Remote Docker socket URL when using the SSH transport")
-
-	cmd.Flags().StringVar(&tsTunnelServer, "ts-server", "", "Tinyscale server address")
-	cmd.Flags().StringVar(&tsTunnelCertFile, "ts-cert", "", "Path to mTLS certificate")
-	cmd.Flags().StringVar(&tsTunnelKeyFile, "ts-key", "", "Path to mTLS private key")
-	cmd.Flags().StringVar(&tsTunnelCAFile, "ts-ca", "", "Path to accepted Tinyscale CA certificate")
-	cmd.Flags().BoolVar(&tsTunnelInsecure, "ts-insecure", false, "Skip tlsconfig verification when connecting to Tinyscale server")
-
-	cmd.Flags().StringVar(&logLevelFlag, "log-level", "info", "Log level")
+	cmd.Flags().StringVar(&listenAddr, "listen", "127.0.0.1:2375", i18n.T("daemon.start.flag.listen"))
+	cmd.Flags().StringVar(&sshUser, "ssh-user", "root", i18n.T("daemon.start.flag.ssh-user"))
+	cmd.Flags().StringVar(&sshHost, "ssh-host", "", i18n.T("daemon.start.flag.ssh-host"))
+	cmd.Flags().StringVar(&sshKeyPath, "ssh-key", os.Getenv("HOME")+"/.ssh/id_rsa", i18n.T("daemon.start.flag.ssh-key"))
+	cmd.Flags().StringVar(&remoteDocker, "remote-docker", "unix:///var/run/docker.sock", i18n.T("daemon.start.flag.remote-docker"))
+	cmd.Flags().StringVar(&guestAddr, "guest-addr", "127.0.0.1:2090", i18n.T("daemon.start.flag.guest-addr"))
+
+	cmd.Flags().StringVar(&tsTunnelServer, "ts-server", "", i18n.T("daemon.start.flag.ts-server"))
+	cmd.Flags().StringVar(&tsTunnelCertFile, "ts-cert", "", i18n.T("daemon.start.flag.ts-cert"))
+	cmd.Flags().StringVar(&tsTunnelKeyFile, "ts-key", "", i18n.T("daemon.start.flag.ts-key"))
+	cmd.Flags().StringVar(&tsTunnelCAFile, "ts-ca", "", i18n.T("daemon.start.flag.ts-ca"))
+	cmd.Flags().BoolVar(&tsTunnelInsecure, "ts-insecure", false, i18n.T("daemon.start.flag.ts-insecure"))
+
+	cmd.Flags().BoolVar(&allowPublicPorts, "allow-public-ports", false, i18n.T("daemon.start.flag.allow-public-ports"))
+	cmd.Flags().BoolVar(&remapPorts, "remap-ports", false, i18n.T("daemon.start.flag.remap-ports"))
+	cmd.Flags().BoolVar(&containerIPs, "container-ips", false, i18n.T("daemon.start.flag.container-ips"))
+	cmd.Flags().StringVar(&dnsListenAddr, "dns-listen", "127.0.0.1:15353", i18n.T("daemon.start.flag.dns-listen"))
+	cmd.Flags().StringVar(&httpRouterAddr, "http-router", "", i18n.T("daemon.start.flag.http-router"))
+	cmd.Flags().StringVar(&httpRouterCertFile, "http-router-cert", "", i18n.T("daemon.start.flag.http-router-cert"))
+	cmd.Flags().StringVar(&httpRouterKeyFile, "http-router-key", "", i18n.T("daemon.start.flag.http-router-key"))
+	cmd.Flags().StringVar(&reversePorts, "reverse-ports", "", i18n.T("daemon.start.flag.reverse-ports"))
+	cmd.Flags().StringVar(&reverseListenAddr, "reverse-listen", "172.17.0.1", i18n.T("daemon.start.flag.reverse-listen"))
+	cmd.Flags().BoolVar(&autoForward, "auto-forward", false, i18n.T("daemon.start.flag.auto-forward"))
+	cmd.Flags().StringVar(&dependencyDirs, "dependency-dirs", "", i18n.T("daemon.start.flag.dependency-dirs"))
+	cmd.Flags().Uint64Var(&syncMaxEntries, "sync-max-entries", 100000, i18n.T("daemon.start.flag.sync-max-entries"))
+	cmd.Flags().StringVar(&syncMaxSize, "sync-max-size", "2GB", i18n.T("daemon.start.flag.sync-max-size"))
+
+	cmd.Flags().StringVar(&logLevelFlag, "log-level", "info", i18n.T("daemon.flag.log-level"))
 	return cmd
 }
 
 func watchTerminationSignal(fileTermination chan<- bool, logger *logging.Logger) error {
 	terminatePath, err := daemon.PidTerminatePath()
 	if err != nil {
-		return fmt.Errorf("unable to compute terminate file path: %w", err)
+		return i18n.Errorf("daemon.error.terminate-path", err)
 	}
 
 	// Create a file watcher
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
-		return fmt.Errorf("unable to create file watcher: %w", err)
+		return i18n.Errorf("daemon.start.error.watcher", err)
 	}
 
 	// Watch the daemon directory for file creation events
 	daemonDir := filepath.Dir(terminatePath)
 	if err := watcher.Add(daemonDir); err != nil {
 		watcher.Close()
-		return fmt.Errorf("unable to watch daemon directory: %w", err)
+		return i18n.Errorf("daemon.start.error.watch-dir", err)
 	}
 
 	logger.Infof("Watching for termination signal at: %s", terminatePath)
@@ -0,0 +1,97 @@
+package tsctl
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/teamycloud/tsctl/pkg/docker-proxy/types"
+	"github.com/teamycloud/tsctl/pkg/tsctl/i18n"
+)
+
+func NewStatusCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: i18n.T("daemon.status.short"),
+		Long:  i18n.T("daemon.status.long"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var status types.DaemonStatus
+			if err := controlRequest(http.MethodGet, "/tinyscale/v1/daemon/status", nil, &status); err != nil {
+				return err
+			}
+
+			fmt.Println(i18n.T("daemon.status.summary", status.PID, status.TransportType, status.Remote, status.ListenAddr))
+			fmt.Println()
+
+			if len(status.PortForwards) == 0 && len(status.ReverseForwards) == 0 {
+				fmt.Println(i18n.T("daemon.status.no-forwards"))
+				return nil
+			}
+
+			var warnings []string
+			if len(status.PortForwards) > 0 {
+				writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+				fmt.Fprintln(writer, i18n.T("daemon.status.header"))
+				for _, forward := range status.PortForwards {
+					fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", shortContainerID(forward.ContainerID),
+						forward.LocalAddress, forward.RemotePort, forward.ContainerPort, forwardState(forward.Active))
+					if forward.Warning != "" {
+						warnings = append(warnings, fmt.Sprintf("%s: %s", shortContainerID(forward.ContainerID), forward.Warning))
+					}
+				}
+				_ = writer.Flush()
+			}
+
+			if len(status.ReverseForwards) > 0 {
+				if len(status.PortForwards) > 0 {
+					fmt.Println()
+				}
+				writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+				fmt.Fprintln(writer, i18n.T("daemon.status.reverse-header"))
+				for _, forward := range status.ReverseForwards {
+					containers := make([]string, 0, len(forward.Containers))
+					for _, containerID := range forward.Containers {
+						containers = append(containers, shortContainerID(containerID))
+					}
+					fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", forward.RemoteAddress, forward.LocalPort,
+						strings.Join(containers, ","), forwardState(forward.Active))
+					if forward.Warning != "" {
+						warnings = append(warnings, forward.Warning)
+					}
+				}
+				_ = writer.Flush()
+			}
+
+			if len(warnings) > 0 {
+				fmt.Println()
+				fmt.Println(i18n.T("daemon.status.warnings"))
+				for _, warning := range warnings {
+					fmt.Printf("  %s\n", warning)
+				}
+			}
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	return cmd
+}
+
+// forwardState returns the state column of a forward
+func forwardState(active bool) string {
+	if active {
+		return i18n.T("daemon.status.state.active")
+	}
+	return i18n.T("daemon.status.state.inactive")
+}
+
+// shortContainerID truncates a container ID the way the Docker CLI shows it
+func shortContainerID(containerID string) string {
+	if len(containerID) > 12 {
+		return containerID[:12]
+	}
+	return containerID
+}
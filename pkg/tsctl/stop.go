@@ -15,6 +15,7 @@ import (
 	"github.com/teamycloud/tsctl/pkg/daemon"
 	_ "github.com/teamycloud/tsctl/pkg/ts-tunnel/forwarding-protocol"
 	_ "github.com/teamycloud/tsctl/pkg/ts-tunnel/synchronization-protocol"
+	"github.com/teamycloud/tsctl/pkg/tsctl/i18n"
 )
 
 func NewStopCommand() *cobra.Command {
@@ -22,13 +23,13 @@ func NewStopCommand() *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:   "stop",
-		Short: "Stop the Tinyscale proxy daemon",
-		Long:  `Stop the running Tinyscale local TCP proxy server`,
+		Short: i18n.T("daemon.stop.short"),
+		Long:  i18n.T("daemon.stop.long"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Create the root logger.
 			logLevel := logging.LevelInfo
 			if l, ok := logging.NameToLevel(logLevelFlag); !ok {
-				fmt.Printf("WARNING: invalid log level specified in environment: %s, default log level 'info' will be used\n", logLevelFlag)
+				fmt.Println(i18n.T("daemon.warning.log-level", logLevelFlag))
 			} else {
 				logLevel = l
 			}
@@ -36,17 +37,17 @@ func NewStopCommand() *cobra.Command {
 
 			pidPath, err := daemon.PidPath()
 			if err != nil {
-				return fmt.Errorf("unable to compute daemon pid file path: %w", err)
+				return i18n.Errorf("daemon.stop.error.pid-path", err)
 			}
 
 			file, err := os.OpenFile(pidPath, os.O_RDONLY, 0)
 			if err != nil {
-				return fmt.Errorf("unable to open daemon pid file: %w", err)
+				return i18n.Errorf("daemon.stop.error.pid-open", err)
 			}
 			bytes, err := io.ReadAll(file)
 			_ = file.Close()
 			if err != nil {
-				return fmt.Errorf("unable to read daemon pid file: %w", err)
+				return i18n.Errorf("daemon.stop.error.pid-read", err)
 			}
 
 			// Trim any whitespace from the PID string
@@ -54,19 +55,19 @@ func NewStopCommand() *cobra.Command {
 			logger.Debugf("Read PID from file: '%s' (length: %d)\n", pidStr, len(pidStr))
 
 			if pid, err := strconv.Atoi(pidStr); err != nil {
-				return fmt.Errorf("invalid pid read from daemon pid file: %w", err)
+				return i18n.Errorf("daemon.stop.error.pid-invalid", err)
 			} else {
 				// Create terminate file with the PID content
 				terminatePath, err := daemon.PidTerminatePath()
 				if err != nil {
-					return fmt.Errorf("unable to compute terminate file path: %w", err)
+					return i18n.Errorf("daemon.error.terminate-path", err)
 				}
 
 				logger.Debugf("Creating termination file at: %s\n", terminatePath)
 				logger.Debugf("Writing PID: %d\n", pid)
 
 				if err := os.WriteFile(terminatePath, []byte(pidStr), 0644); err != nil {
-					return fmt.Errorf("unable to create terminate file: %w", err)
+					return i18n.Errorf("daemon.stop.error.terminate-file", err)
 				}
 
 				// Verify the file was written correctly
@@ -74,13 +75,13 @@ func NewStopCommand() *cobra.Command {
 					logger.Debugf("Verified termination file content: '%s'\n", string(content))
 				}
 
-				logger.Infof("Sent termination signal to daemon process (PID: %d)\n", pid)
+				logger.Info(i18n.T("daemon.stop.done", pid))
 				return nil
 			}
 		},
 		SilenceUsage: true,
 	}
 
-	cmd.Flags().StringVar(&logLevelFlag, "log-level", "info", "Log level")
+	cmd.Flags().StringVar(&logLevelFlag, "log-level", "info", i18n.T("daemon.flag.log-level"))
 	return cmd
 }
@@ -0,0 +1,98 @@
+package tsctl
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/teamycloud/tsctl/pkg/docker-proxy/types"
+	"github.com/teamycloud/tsctl/pkg/tsctl/i18n"
+)
+
+func NewSyncCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: i18n.T("sync.short"),
+		Long:  i18n.T("sync.long"),
+	}
+
+	cmd.AddCommand(NewSyncCreateCommand())
+	cmd.AddCommand(NewSyncListCommand())
+	cmd.AddCommand(NewSyncTerminateCommand())
+
+	return cmd
+}
+
+func NewSyncCreateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <local-dir> [remote-path]",
+		Short: i18n.T("sync.create.short"),
+		Long:  i18n.T("sync.create.long"),
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// The daemon resolves paths from its own working directory
+			localPath, err := filepath.Abs(args[0])
+			if err != nil {
+				return i18n.Errorf("sync.error.local-path", err)
+			}
+			req := types.SyncRequest{LocalPath: localPath}
+			if len(args) == 2 {
+				req.RemotePath = args[1]
+			}
+
+			var session types.SessionStatus
+			if err := controlRequest(http.MethodPost, "/tinyscale/v1/daemon/syncs", &req, &session); err != nil {
+				return err
+			}
+
+			fmt.Println(i18n.T("sync.created", session.Source, session.Destination, session.Name))
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	return cmd
+}
+
+func NewSyncListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: i18n.T("sync.list.short"),
+		Long:  i18n.T("sync.list.long"),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var sessions []types.SessionStatus
+			if err := controlRequest(http.MethodGet, "/tinyscale/v1/daemon/sessions", nil, &sessions); err != nil {
+				return err
+			}
+
+			syncs := make([]types.SessionStatus, 0, len(sessions))
+			for _, session := range sessions {
+				if session.Kind == types.SessionKindSync {
+					syncs = append(syncs, session)
+				}
+			}
+			printSessions(syncs)
+			return nil
+		},
+		SilenceUsage: true,
+	}
+
+	return cmd
+}
+
+func NewSyncTerminateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "terminate <session>...",
+		Short: i18n.T("sync.terminate.short"),
+		Long:  i18n.T("daemon.sessions.terminate.long"),
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return terminateSessions(args)
+		},
+		SilenceUsage: true,
+	}
+
+	return cmd
+}
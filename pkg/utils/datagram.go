@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// MaxDatagramSize is the largest datagram that can be framed
+const MaxDatagramSize = 65535
+
+// WriteDatagram writes a datagram to a stream, prefixed with its length as
+// a 2-byte big-endian integer
+func WriteDatagram(w io.Writer, payload []byte) error {
+	if len(payload) > MaxDatagramSize {
+		return fmt.Errorf("datagram of %d bytes exceeds the maximum size", len(payload))
+	}
+
+	frame := make([]byte, 2+len(payload))
+	binary.BigEndian.PutUint16(frame, uint16(len(payload)))
+	copy(frame[2:], payload)
+	_, err := w.Write(frame)
+	return err
+}
+
+// ReadDatagram reads a datagram written by WriteDatagram into buf, which must
+// hold MaxDatagramSize bytes, and returns its length
+func ReadDatagram(r io.Reader, buf []byte) (int, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, err
+	}
+
+	size := int(binary.BigEndian.Uint16(header[:]))
+	if size > len(buf) {
+		return 0, fmt.Errorf("datagram of %d bytes exceeds the buffer size", size)
+	}
+	if _, err := io.ReadFull(r, buf[:size]); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
@@ -0,0 +1,11 @@
+// Package version holds the tsctl build version.
+package version
+
+// Version is the tsctl version, replaced at build time with
+// -ldflags "-X github.com/teamycloud/tsctl/pkg/version.Version=<version>"
+var Version = "dev"
+
+// UserAgent returns the User-Agent header value for requests sent by tsctl
+func UserAgent() string {
+	return "tsctl/" + Version
+}
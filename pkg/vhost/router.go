@@ -0,0 +1,151 @@
+package vhost
+
+import (
+	"errors"
+	"html/template"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+
+	"github.com/mutagen-io/mutagen/pkg/logging"
+	"github.com/teamycloud/tsctl/pkg/docker-proxy/types"
+)
+
+// indexTemplate lists the routes on any hostname that is not routed
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>tsctl routes</title></head>
+<body>
+<h1>tsctl routes</h1>
+{{if .Routes}}
+<table>
+<tr><th>Host</th><th>Container</th><th>Port</th><th>Forwarded on</th></tr>
+{{range .Routes}}
+<tr><td><a href="{{$.Scheme}}://{{.Host}}{{$.Port}}/">{{.Host}}</a></td><td>{{.ContainerName}}</td><td>{{.ContainerPort}}</td><td>{{.Target}}</td></tr>
+{{end}}
+</table>
+{{else}}
+<p>No forwarded ports yet.</p>
+{{end}}
+</body>
+</html>
+`))
+
+// Router is a reverse proxy routing requests by hostname to the local
+// addresses ports of containers are forwarded on. WebSocket upgrades are
+// proxied as well.
+type Router struct {
+	addr     string
+	certFile string
+	keyFile  string
+	routes   func() []types.HTTPRoute
+	logger   *logging.Logger
+
+	server *http.Server
+}
+
+// NewRouter creates a router listening on addr, serving TLS when certFile and
+// keyFile are set. routes is called on each request, so routes follow the
+// port forwards as containers start and stop.
+func NewRouter(addr string, certFile string, keyFile string, routes func() []types.HTTPRoute, logger *logging.Logger) *Router {
+	router := &Router{
+		addr:     addr,
+		certFile: certFile,
+		keyFile:  keyFile,
+		routes:   routes,
+		logger:   logger,
+	}
+	router.server = &http.Server{
+		Addr:    addr,
+		Handler: router,
+	}
+	return router
+}
+
+// ListenAndServe serves requests until Close is called
+func (r *Router) ListenAndServe() error {
+	r.logger.Infof("HTTP router listening on %s", r.addr)
+
+	var err error
+	if r.certFile != "" && r.keyFile != "" {
+		err = r.server.ListenAndServeTLS(r.certFile, r.keyFile)
+	} else {
+		err = r.server.ListenAndServe()
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Close stops the router
+func (r *Router) Close() error {
+	return r.server.Close()
+}
+
+// ServeHTTP proxies a request to the route of its hostname, or serves the
+// index page
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	host := strings.ToLower(req.Host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(host, ".")
+
+	routes := r.routes()
+	for _, route := range routes {
+		if route.Host == host {
+			r.proxy(w, req, route)
+			return
+		}
+	}
+
+	r.serveIndex(w, req, routes)
+}
+
+// proxy forwards a request to the target of a route
+func (r *Router) proxy(w http.ResponseWriter, req *http.Request, route types.HTTPRoute) {
+	target := &url.URL{Scheme: "http", Host: route.Target}
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			// Keep the original host, applications build links from it
+			pr.Out.Host = pr.In.Host
+			pr.SetXForwarded()
+		},
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			r.logger.Debugf("Failed to proxy %s to %s: %v", req.Host, route.Target, err)
+			http.Error(w, "Container port "+route.ContainerPort+" of "+route.ContainerName+" is not reachable: "+err.Error(), http.StatusBadGateway)
+		},
+	}
+	proxy.ServeHTTP(w, req)
+}
+
+// serveIndex lists the routes
+func (r *Router) serveIndex(w http.ResponseWriter, req *http.Request, routes []types.HTTPRoute) {
+	if req.URL.Path != "/" {
+		http.NotFound(w, req)
+		return
+	}
+
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	port := ""
+	if _, p, err := net.SplitHostPort(req.Host); err == nil {
+		port = ":" + p
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	data := struct {
+		Scheme string
+		Port   string
+		Routes []types.HTTPRoute
+	}{scheme, port, routes}
+	if err := indexTemplate.Execute(w, data); err != nil {
+		r.logger.Debugf("Failed to render index page: %v", err)
+	}
+}